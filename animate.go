@@ -0,0 +1,173 @@
+package sdl
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// AnimatedTileManager is an optional extension of TileManager for tile
+// managers providing animated tiles, such as for water, fire or torches.
+// When implemented, the driver cycles through the frames of animated cells
+// on its own timer, so that the application does not need to flush new
+// frames for animations. Animations are not supported in glyph mode (see
+// Config.GlyphColors).
+type AnimatedTileManager interface {
+	TileManager
+
+	// GetFrames returns the animation frames for a cell, along with the
+	// duration of each frame. It returns no frames for cells that are not
+	// animated, in which case GetImage is used as usual.
+	GetFrames(gruid.Cell) ([]image.Image, time.Duration)
+}
+
+// animation contains the frame textures of an animated tile.
+type animation struct {
+	frames   []texture
+	duration time.Duration
+}
+
+// frame returns the index of the frame to be shown at a given time.
+func (anim *animation) frame(d time.Duration) int {
+	return int(d/anim.duration) % len(anim.frames)
+}
+
+// animationFrame returns the current frame texture for a cell at the given
+// grid index, if animated, and records the index for later updates.
+func (dr *Driver) animationFrame(cell gruid.Cell, key gruid.Cell, i int) (texture, bool) {
+	tm, ok := dr.tm.(AnimatedTileManager)
+	if !ok || dr.glyphColors != nil {
+		return nil, false
+	}
+	anim, ok := dr.animations[key]
+	if !ok {
+		anim = dr.newAnimation(tm, cell)
+		if dr.animations == nil {
+			dr.animations = make(map[gruid.Cell]*animation)
+		}
+		dr.animations[key] = anim
+	}
+	if anim == nil {
+		delete(dr.animFrames, i)
+		return nil, false
+	}
+	if dr.animStart.IsZero() {
+		dr.animStart = time.Now()
+	}
+	if dr.animFrames == nil {
+		dr.animFrames = make(map[int]int)
+	}
+	n := anim.frame(time.Since(dr.animStart))
+	dr.animFrames[i] = n
+	return anim.frames[n], true
+}
+
+// newAnimation returns the animation for a cell, or nil if the cell is not
+// animated.
+func (dr *Driver) newAnimation(tm AnimatedTileManager, cell gruid.Cell) *animation {
+	ghost := cell.Style.Attrs&dr.ghostAttr != 0
+	c := cell
+	c.Style.Attrs &^= dr.ghostAttr
+	imgs, d := tm.GetFrames(c)
+	if len(imgs) == 0 {
+		return nil
+	}
+	if d <= 0 {
+		d = time.Second
+	}
+	anim := &animation{duration: d}
+	for _, img := range imgs {
+		if img == nil {
+			dr.handleError(&TileError{Cell: c, Err: fmt.Errorf("nil animation frame")})
+			dr.destroyAnimation(anim)
+			return nil
+		}
+		tx, err := dr.newTexture(img)
+		if err == nil && ghost {
+			err = dr.ghostTexture(tx)
+			if err != nil {
+				tx.Destroy()
+			}
+		}
+		if err != nil {
+			dr.addTextureFailure()
+			dr.handleError(fmt.Errorf("animation: texture: %v", err))
+			dr.destroyAnimation(anim)
+			return nil
+		}
+		anim.frames = append(anim.frames, tx)
+	}
+	return anim
+}
+
+// destroyAnimation releases an animation's textures.
+func (dr *Driver) destroyAnimation(anim *animation) {
+	for _, tx := range anim.frames {
+		err := tx.Destroy()
+		if err != nil {
+			dr.logf("animation destroy: %v", err)
+		}
+	}
+}
+
+// clearAnimations releases all animation textures.
+func (dr *Driver) clearAnimations() {
+	for _, anim := range dr.animations {
+		if anim != nil {
+			dr.destroyAnimation(anim)
+		}
+	}
+	dr.animations = nil
+	dr.animFrames = nil
+}
+
+// checkAnimations redraws and presents animated cells whose frame changed.
+func (dr *Driver) checkAnimations() {
+	if len(dr.animFrames) == 0 || dr.suspended || !dr.init {
+		return
+	}
+	now := time.Now()
+	if now.Sub(dr.animChecked) < effectInterval {
+		return
+	}
+	dr.animChecked = now
+	d := now.Sub(dr.animStart)
+	changed := dr.animChanged[:0]
+	for i, n := range dr.animFrames {
+		if i >= len(dr.grid) {
+			delete(dr.animFrames, i)
+			continue
+		}
+		anim := dr.animations[dr.key(dr.grid[i])]
+		if anim != nil && anim.frame(d) != n {
+			changed = append(changed, i)
+		}
+	}
+	dr.animChanged = changed
+	dr.redrawCells(changed)
+}
+
+// redrawCells redraws and presents the grid cells with the given indices,
+// outside of Flush. If there are overlays, or cells overlap, the whole grid
+// is refreshed instead.
+func (dr *Driver) redrawCells(cells []int) {
+	if len(cells) == 0 {
+		return
+	}
+	if dr.hasOverlays() || dr.layout == IsometricLayout {
+		dr.needRefresh = true
+		return
+	}
+	w := int(dr.width)
+	dr.beginDraw()
+	var ferr *FlushError
+	for _, i := range cells {
+		ferr = addError(ferr, dr.draw(dr.grid[i], i%w, i/w))
+	}
+	if ferr != nil {
+		dr.handleError(ferr)
+	}
+	dr.present()
+}
@@ -0,0 +1,187 @@
+package sdl
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"  // register GIF decoder
+	_ "image/jpeg" // register JPEG decoder
+	_ "image/png"  // register PNG decoder
+	"io/fs"
+
+	_ "golang.org/x/image/bmp" // register BMP decoder
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+
+	"github.com/anaseto/gruid"
+)
+
+// LoadImage loads a PNG, BMP, GIF or JPEG image from a file system, such as
+// an embed.FS. It can be used, for example, for the window icon (see
+// Config.WindowIcon).
+//
+// When building with the sdlimage tag, images are decoded with the SDL_image
+// library instead, which may support more formats, such as WebP. Moreover,
+// textures are then created from such images without intermediate
+// conversion, which cuts load time for large spritesheets. This requires
+// the SDL2_image library to be installed.
+func LoadImage(fsys fs.FS, name string) (image.Image, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	img, err := decodeImage(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return img, nil
+}
+
+// LoadFace loads a TrueType or OpenType font from a file system, such as an
+// embed.FS, and returns a face with the given size in points and DPI. The
+// face can be used with gruid's tiles.Drawer for building a TileManager.
+func LoadFace(fsys fs.FS, name string, size, dpi float64) (font.Face, error) {
+	return loadFace(fsys, name, size, dpi, font.HintingFull)
+}
+
+// loadFace loads a font face with the given hinting mode.
+func loadFace(fsys fs.FS, name string, size, dpi float64, hinting font.Hinting) (font.Face, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	ft, err := opentype.Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	face, err := opentype.NewFace(ft, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     dpi,
+		Hinting: hinting,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return face, nil
+}
+
+// Tileset represents a sheet of tiles of the same size, arranged in rows
+// from left to right and top to bottom. It can be used for building a
+// TileManager.
+type Tileset struct {
+	img  image.Image
+	size gruid.Point
+	cols int
+	rows int
+}
+
+// NewTileset returns a tileset using a given sheet image and tile size.
+func NewTileset(img image.Image, size gruid.Point) (*Tileset, error) {
+	if size.X <= 0 || size.Y <= 0 {
+		return nil, fmt.Errorf("invalid tile size: %v", size)
+	}
+	if _, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); !ok {
+		rgba := image.NewRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Rect, img, rgba.Rect.Min, draw.Src)
+		img = rgba
+	}
+	b := img.Bounds()
+	ts := &Tileset{img: img, size: size, cols: b.Dx() / size.X, rows: b.Dy() / size.Y}
+	if ts.cols == 0 || ts.rows == 0 {
+		return nil, fmt.Errorf("tileset image smaller than tile size %v", size)
+	}
+	return ts, nil
+}
+
+// LoadTileset loads a tileset sheet image from a file system, such as an
+// embed.FS, with the given tile size.
+func LoadTileset(fsys fs.FS, name string, size gruid.Point) (*Tileset, error) {
+	img, err := LoadImage(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := NewTileset(img, size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return ts, nil
+}
+
+// Len returns the number of tiles in the tileset.
+func (ts *Tileset) Len() int {
+	return ts.cols * ts.rows
+}
+
+// TileSize returns the size of the tiles in pixels.
+func (ts *Tileset) TileSize() gruid.Point {
+	return ts.size
+}
+
+// Tile returns the i-th tile, or nil if out of range.
+func (ts *Tileset) Tile(i int) image.Image {
+	if i < 0 || i >= ts.Len() {
+		return nil
+	}
+	return ts.TileAt(i%ts.cols, i/ts.cols)
+}
+
+// TileAt returns the tile at the given column and row, or nil if out of
+// range.
+func (ts *Tileset) TileAt(x, y int) image.Image {
+	if x < 0 || x >= ts.cols || y < 0 || y >= ts.rows {
+		return nil
+	}
+	min := ts.img.Bounds().Min
+	r := image.Rect(x*ts.size.X, y*ts.size.Y, (x+1)*ts.size.X, (y+1)*ts.size.Y).Add(min)
+	return ts.img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}).SubImage(r)
+}
+
+// Cursor represents a mouse cursor image.
+type Cursor struct {
+	Image image.Image // cursor image
+	Hot   image.Point // hot spot position within the image
+}
+
+// LoadCursor loads a cursor image from a file system, such as an embed.FS,
+// with the given hot spot.
+func LoadCursor(fsys fs.FS, name string, hot image.Point) (*Cursor, error) {
+	img, err := LoadImage(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{Image: img, Hot: hot}, nil
+}
+
+// SetCursor changes the mouse cursor shown over the window. A nil cursor
+// restores the system's default cursor. If the driver is already running,
+// change will take effect with next Flush so that the function is thread
+// safe.
+func (dr *Driver) SetCursor(c *Cursor) {
+	fn := func() {
+		dr.cursor = c
+		if dr.init {
+			dr.setCursor()
+		}
+	}
+	if dr.init {
+		dr.queueSet("SetCursor", fn)
+	} else {
+		fn()
+	}
+}
+
+func (dr *Driver) setCursor() {
+	var err error
+	if dr.cursor == nil {
+		err = dr.backend.setCursor(nil, image.Point{})
+	} else {
+		err = dr.backend.setCursor(dr.cursor.Image, dr.cursor.Hot)
+	}
+	if err != nil {
+		dr.logf("set cursor: %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package sdl
+
+import (
+	"image"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// atlasSize is the maximum width and height of atlas pages.
+const atlasSize = 2048
+
+// atlasPage is a texture into which tiles are packed in rows.
+type atlasPage struct {
+	tx         texture
+	w, h       int32
+	x, y, rowH int32 // position of the next tile, and current row height
+
+	// Current texture state, so that it is changed only when needed.
+	alpha uint8
+	blend sdl.BlendMode
+	mod   [3]uint8
+}
+
+// atlasTile is a texture implementation referring to a region of an atlas
+// page. It keeps its own modulation and blend mode, which are applied to
+// the page before copying.
+type atlasTile struct {
+	page  *atlasPage
+	rect  sdl.Rect
+	alpha uint8
+	blend sdl.BlendMode
+	mod   [3]uint8
+}
+
+func (at *atlasTile) SetAlphaMod(alpha uint8) error {
+	at.alpha = alpha
+	return nil
+}
+
+func (at *atlasTile) SetColorMod(r, g, b uint8) error {
+	at.mod = [3]uint8{r, g, b}
+	return nil
+}
+
+func (at *atlasTile) SetBlendMode(bm sdl.BlendMode) error {
+	at.blend = bm
+	return nil
+}
+
+// Destroy does nothing: the space is reclaimed when the atlas is cleared.
+func (at *atlasTile) Destroy() error {
+	return nil
+}
+
+// copy copies the tile to the current rendering target.
+func (at *atlasTile) copy(r renderer, dst *sdl.Rect) error {
+	if err := at.apply(); err != nil {
+		return err
+	}
+	return r.copy(at.page.tx, &at.rect, dst)
+}
+
+// apply sets the tile's alpha, blend mode and color modulation on its page
+// texture, if they differ.
+func (at *atlasTile) apply() error {
+	p := at.page
+	if p.alpha != at.alpha {
+		if err := p.tx.SetAlphaMod(at.alpha); err != nil {
+			return err
+		}
+		p.alpha = at.alpha
+	}
+	if p.blend != at.blend {
+		if err := p.tx.SetBlendMode(at.blend); err != nil {
+			return err
+		}
+		p.blend = at.blend
+	}
+	if p.mod != at.mod {
+		if err := p.tx.SetColorMod(at.mod[0], at.mod[1], at.mod[2]); err != nil {
+			return err
+		}
+		p.mod = at.mod
+	}
+	return nil
+}
+
+// newTexture returns a new tile texture for an image, packed into the atlas
+// or reusing a streaming texture if enabled.
+func (dr *Driver) newTexture(img image.Image) (texture, error) {
+	img = dr.filterImage(img)
+	switch {
+	case dr.atlas:
+		return dr.addToAtlas(toNRGBA(img))
+	case dr.streaming:
+		return dr.streamTexture(toNRGBA(img))
+	}
+	return dr.renderer.createTexture(img)
+}
+
+// imageStaged implements staged for tiles packed into the atlas or uploaded
+// into streaming textures.
+type imageStaged struct {
+	img    *image.NRGBA
+	opaque bool
+}
+
+func (st imageStaged) free() {}
+
+// stageImage prepares an image for texture creation with uploadTile. It may
+// be called from any goroutine.
+func (dr *Driver) stageImage(img image.Image) (staged, error) {
+	img = dr.filterImage(img)
+	if !dr.atlas && !dr.streaming {
+		return dr.renderer.stageTexture(img)
+	}
+	m, opaque := toNRGBA(img)
+	return imageStaged{m, opaque}, nil
+}
+
+// uploadTile returns a new tile texture from staged data.
+func (dr *Driver) uploadTile(st staged) (texture, error) {
+	if ist, ok := st.(imageStaged); ok {
+		if dr.atlas {
+			return dr.addToAtlas(ist.img, ist.opaque)
+		}
+		return dr.streamTexture(ist.img, ist.opaque)
+	}
+	return dr.renderer.uploadTexture(st)
+}
+
+// addToAtlas packs an image into the last atlas page, creating a new page
+// if it does not fit. Images too big for a page get their own texture. Tiles
+// with transparency are alpha-blended.
+func (dr *Driver) addToAtlas(img *image.NRGBA, opaque bool) (texture, error) {
+	w, h := int32(img.Rect.Dx()), int32(img.Rect.Dy())
+	size := dr.atlasPageSize()
+	if w > size || h > size {
+		return dr.renderer.createTexture(img)
+	}
+	var p *atlasPage
+	if n := len(dr.atlasPages); n > 0 {
+		p = dr.atlasPages[n-1]
+		if p.x+w > p.w {
+			// Next row.
+			p.x, p.y, p.rowH = 0, p.y+p.rowH, 0
+		}
+		if p.y+h > p.h {
+			p = nil
+		}
+	}
+	if p == nil {
+		tx, err := dr.renderer.createAtlas(size, size)
+		if err != nil {
+			return nil, err
+		}
+		for _, err := range []error{tx.SetAlphaMod(255), tx.SetBlendMode(sdl.BLENDMODE_NONE), tx.SetColorMod(255, 255, 255)} {
+			if err != nil {
+				tx.Destroy()
+				return nil, err
+			}
+		}
+		p = &atlasPage{tx: tx, w: size, h: size, alpha: 255, blend: sdl.BLENDMODE_NONE, mod: [3]uint8{255, 255, 255}}
+		dr.atlasPages = append(dr.atlasPages, p)
+	}
+	rect := sdl.Rect{X: p.x, Y: p.y, W: w, H: h}
+	err := dr.renderer.updateTexture(p.tx, rect, img)
+	if err != nil {
+		return nil, err
+	}
+	p.x += w
+	if h > p.rowH {
+		p.rowH = h
+	}
+	at := &atlasTile{page: p, rect: rect, alpha: 255, blend: sdl.BLENDMODE_NONE, mod: p.mod}
+	if !opaque {
+		at.blend = sdl.BLENDMODE_BLEND
+	}
+	return at, nil
+}
+
+// atlasPageSize returns the size of atlas pages, taking into account the
+// renderer's maximum texture size. It is computed once, until the atlas is
+// cleared, as the renderer may then have been recreated.
+func (dr *Driver) atlasPageSize() int32 {
+	if dr.atlasMax > 0 {
+		return dr.atlasMax
+	}
+	size := int32(atlasSize)
+	if info, err := dr.renderer.GetInfo(); err == nil {
+		if info.MaxTextureWidth > 0 && info.MaxTextureWidth < size {
+			size = info.MaxTextureWidth
+		}
+		if info.MaxTextureHeight > 0 && info.MaxTextureHeight < size {
+			size = info.MaxTextureHeight
+		}
+	}
+	dr.atlasMax = size
+	return size
+}
+
+// clearAtlas destroys the atlas pages.
+func (dr *Driver) clearAtlas() {
+	for _, p := range dr.atlasPages {
+		err := p.tx.Destroy()
+		if err != nil {
+			dr.logf("atlas destroy: %v", err)
+		}
+	}
+	dr.atlasPages = nil
+	dr.atlasMax = 0
+}
+
+// copyTexture copies a tile texture to the current rendering target.
+func (dr *Driver) copyTexture(tx texture, dst *sdl.Rect) error {
+	if at, ok := tx.(*atlasTile); ok {
+		return at.copy(dr.renderer, dst)
+	}
+	return dr.renderer.copy(tx, nil, dst)
+}
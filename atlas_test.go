@@ -0,0 +1,70 @@
+package sdl
+
+import (
+	"testing"
+
+	"github.com/anaseto/gruid"
+)
+
+func TestAtlas(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"grid", Config{}},
+		{"oversized", Config{TileManager: tallTileManager{}}},
+		{"isometric", Config{Layout: IsometricLayout}},
+		{"limited", Config{CacheLimit: 4}},
+	}
+	for _, tt := range tests {
+		cfg := tt.cfg
+		cfg.Atlas = true
+		dr, _ := newTestDriver(t, cfg)
+		ref, _ := newTestDriver(t, tt.cfg)
+		for i, frame := range randomFrames(20, 10, 5, "abcdefT.") {
+			dr.Flush(frame)
+			ref.Flush(frame)
+			if p, ok := sameImages(screenshot(t, dr), screenshot(t, ref)); !ok {
+				t.Errorf("%s: frame %d: rendering differs at %v", tt.name, i, p)
+				break
+			}
+		}
+		if len(dr.atlasPages) != 1 {
+			t.Errorf("%s: %d atlas pages, want 1", tt.name, len(dr.atlasPages))
+		}
+		for key, tx := range dr.textures {
+			if _, ok := tx.(*atlasTile); !ok {
+				t.Errorf("%s: texture for %v is not in the atlas", tt.name, key)
+			}
+		}
+	}
+}
+
+func TestAtlasPacking(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{Atlas: true, TileManager: tallTileManager{}})
+	dr.Flush(testFrame(10, 5, "abTc"))
+	want := map[rune][4]int32{
+		'a': {0, 0, 8, 8},
+		'b': {8, 0, 8, 8},
+		'T': {16, 0, 8, 16},
+		'c': {24, 0, 8, 8},
+	}
+	for r, w := range want {
+		tx, ok := dr.lookupTexture(gruid.Cell{Rune: r})
+		if !ok {
+			t.Errorf("%c: not cached", r)
+			continue
+		}
+		rect := tx.(*atlasTile).rect
+		if got := [4]int32{rect.X, rect.Y, rect.W, rect.H}; got != w {
+			t.Errorf("%c: atlas region %v, want %v", r, got, w)
+		}
+	}
+	if dr.atlasMax != atlasSize {
+		t.Errorf("atlas page size %d, want %d", dr.atlasMax, atlasSize)
+	}
+	dr.ClearCache()
+	if len(dr.atlasPages) != 0 || dr.atlasMax != 0 {
+		t.Errorf("atlas not cleared: %d pages, size %d", len(dr.atlasPages), dr.atlasMax)
+	}
+}
@@ -0,0 +1,98 @@
+package sdl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// defaultBlinkInterval is the default duration of each blinking phase.
+const defaultBlinkInterval = 500 * time.Millisecond
+
+// styleAttrs reports whether some style attributes are rendered by the
+// driver.
+func (dr *Driver) styleAttrs() bool {
+	return dr.reverseAttr|dr.underlineAttr|dr.blinkAttr != 0
+}
+
+// renderAttrs returns the cell whose tile is drawn for a cell at the given
+// grid index, handling attributes rendered by the driver: colors are swapped
+// for reverse video, and blinking cells are drawn blank during the off
+// phase. It also reports whether the cell should be underlined.
+func (dr *Driver) renderAttrs(c gruid.Cell, i int) (gruid.Cell, bool) {
+	if !dr.styleAttrs() {
+		return c, false
+	}
+	st := &c.Style
+	if st.Attrs&dr.reverseAttr != 0 {
+		st.Fg, st.Bg = st.Bg, st.Fg
+		st.Attrs &^= dr.reverseAttr
+	}
+	if st.Attrs&dr.blinkAttr != 0 {
+		st.Attrs &^= dr.blinkAttr
+		if dr.blinking == nil {
+			dr.blinking = make(map[int]bool)
+			dr.blinkStart = time.Now()
+		}
+		dr.blinking[i] = true
+		if dr.blinkOff {
+			c.Rune = ' '
+		}
+	} else if dr.blinking != nil {
+		delete(dr.blinking, i)
+	}
+	underline := st.Attrs&dr.underlineAttr != 0
+	st.Attrs &^= dr.underlineAttr
+	return c, underline
+}
+
+// drawUnderline draws an underline at the bottom of a cell's rectangle.
+func (dr *Driver) drawUnderline(st gruid.Style, rect sdl.Rect) error {
+	var r, g, b uint8 = 255, 255, 255
+	if dr.underlineColor != nil {
+		r, g, b = rgb(dr.underlineColor(st))
+	} else if dr.glyphColors != nil {
+		fg, _ := dr.glyphColors(st)
+		r, g, b = rgb(fg)
+	}
+	r, g, b = dr.filterRGB(r, g, b)
+	h := dr.th / 12
+	if h < 1 {
+		h = 1
+	}
+	rect.Y += rect.H - 2*h
+	rect.H = h
+	err := dr.renderer.SetDrawColor(r, g, b, 255)
+	if err == nil {
+		err = dr.renderer.FillRect(&rect)
+	}
+	if err != nil {
+		return fmt.Errorf("draw: underline: %v", err)
+	}
+	return nil
+}
+
+// checkBlink switches the blinking phase when due, redrawing blinking
+// cells.
+func (dr *Driver) checkBlink() {
+	if len(dr.blinking) == 0 || dr.suspended || !dr.init {
+		return
+	}
+	off := (time.Since(dr.blinkStart)/dr.blinkInterval)%2 == 1
+	if off == dr.blinkOff {
+		return
+	}
+	dr.blinkOff = off
+	cells := dr.animChanged[:0]
+	for i := range dr.blinking {
+		if i >= len(dr.grid) {
+			delete(dr.blinking, i)
+			continue
+		}
+		cells = append(cells, i)
+	}
+	dr.animChanged = cells
+	dr.redrawCells(cells)
+}
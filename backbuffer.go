@@ -0,0 +1,121 @@
+package sdl
+
+import (
+	"image"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// beginDraw makes subsequent drawing happen into the backbuffer, if enabled,
+// creating or resizing it as necessary.
+func (dr *Driver) beginDraw() {
+	if !dr.usesBackbuffer() {
+		return
+	}
+	w, h := dr.windowSize()
+	if dr.bb == nil || dr.bbSize != (image.Point{X: int(w), Y: int(h)}) {
+		if !dr.resizeBackbuffer(w, h) {
+			return
+		}
+	}
+	err := dr.renderer.setTarget(dr.bb)
+	if err != nil {
+		dr.logf("backbuffer: %v", err)
+	}
+}
+
+// resizeBackbuffer replaces the backbuffer with a new one of the given size,
+// keeping previous content. If the backbuffer cannot be created, it is
+// disabled, and the driver draws directly into the window.
+func (dr *Driver) resizeBackbuffer(w, h int32) bool {
+	bb, err := dr.renderer.createTarget(w, h)
+	if err == nil {
+		err = dr.renderer.setTarget(bb)
+	}
+	if err != nil {
+		dr.logf("backbuffer: disabled: %v", err)
+		if bb != nil {
+			bb.Destroy()
+		}
+		dr.destroyBackbuffer()
+		dr.backbuffer = false
+		dr.zoom = zoom{}
+		dr.shake = shake{}
+		dr.endTransition()
+		dr.faded = false
+		dr.glFunc = nil
+		// The window might not show the whole grid.
+		dr.drawn = false
+		dr.invalidateViewports()
+		return false
+	}
+	dr.clear()
+	if dr.bb != nil {
+		rect := sdl.Rect{W: int32(dr.bbSize.X), H: int32(dr.bbSize.Y)}
+		err = dr.renderer.copy(dr.bb, &rect, &rect)
+		if err != nil {
+			dr.logf("backbuffer: %v", err)
+		}
+	}
+	dr.destroyBackbuffer()
+	dr.bb = bb
+	dr.bbSize = image.Point{X: int(w), Y: int(h)}
+	return true
+}
+
+// usesBackbuffer reports whether drawing happens into the backbuffer, either
+// because it was enabled, or because of zooming, effects or an OpenGL callback.
+func (dr *Driver) usesBackbuffer() bool {
+	return dr.backbuffer || dr.zoomed() || dr.shaking() || dr.transitioning() || dr.glFunc != nil
+}
+
+// destroyBackbuffer releases the backbuffer, if any.
+func (dr *Driver) destroyBackbuffer() {
+	if dr.bb == nil {
+		return
+	}
+	err := dr.bb.Destroy()
+	if err != nil {
+		dr.logf("backbuffer destroy: %v", err)
+	}
+	dr.bb = nil
+}
+
+// present presents the drawn content, copying first the backbuffer, if
+// enabled, into the window.
+func (dr *Driver) present() {
+	if dr.usesBackbuffer() && dr.bb != nil {
+		err := dr.renderer.setTarget(nil)
+		if err == nil {
+			rect := sdl.Rect{W: int32(dr.bbSize.X), H: int32(dr.bbSize.Y)}
+			if dr.shaking() {
+				rect.X, rect.Y = dr.shakeOffset()
+				dr.clear()
+			}
+			err = dr.renderer.copy(dr.bb, dr.zoomRect(), &rect)
+			if err == nil && dr.transitioning() {
+				dr.drawTransition(&rect)
+			}
+			if err == nil && dr.glFunc != nil {
+				dr.callGLFunc()
+			}
+		}
+		if err != nil {
+			dr.logf("backbuffer: %v", err)
+		}
+	}
+	dr.renderer.Present()
+	dr.presented = time.Now()
+}
+
+// represent presents the backbuffer again, without involving the
+// application, after the window content was lost. It reports whether it
+// succeeded.
+func (dr *Driver) represent() bool {
+	if !dr.usesBackbuffer() || dr.bb == nil || !dr.drawn {
+		return false
+	}
+	dr.present()
+	return true
+}
@@ -0,0 +1,539 @@
+package sdl
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"unsafe"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// backend abstracts the SDL calls used by the driver for window management,
+// rendering and event handling, so that the driver's logic does not depend on
+// an actual display.
+type backend interface {
+	// init initializes the library.
+	init() error
+
+	// quit releases the library's resources.
+	quit()
+
+	// createWindow creates a window with the given title, size in pixels
+	// and sdl.WindowFlags.
+	createWindow(title string, w, h int32, flags uint32) (window, error)
+
+	// createRenderer creates a renderer for the given window with the
+	// given render driver name and sdl.RendererFlags. An empty name means
+	// the first driver supporting the flags.
+	createRenderer(win window, driver string, flags uint32) (renderer, error)
+
+	// pollEvent returns the next pending event, if any.
+	pollEvent() sdl.Event
+
+	// waitEvent waits for the next event for at most timeout
+	// milliseconds, or indefinitely if timeout is negative. It returns
+	// nil if no event was available in time.
+	waitEvent(timeout int) sdl.Event
+
+	// displayDPI returns the diagonal DPI of the display containing the
+	// window, or zero if unknown.
+	displayDPI(win window) (float32, error)
+
+	// refreshRate returns the refresh rate in Hz of the display
+	// containing the window, or zero if unknown.
+	refreshRate(win window) (int, error)
+
+	// modState returns the current state of modifier keys.
+	modState() sdl.Keymod
+
+	// startTextInput starts accepting text input events.
+	startTextInput()
+
+	// stopTextInput stops accepting text input events.
+	stopTextInput()
+
+	// setCursor sets the mouse cursor to the given image with the given
+	// hot spot, or to the default cursor if the image is nil.
+	setCursor(img image.Image, hot image.Point) error
+
+	// showCursor shows or hides the mouse cursor.
+	showCursor(show bool) error
+
+	// registerEvent registers a new user event type.
+	registerEvent() (uint32, error)
+
+	// pushEvent adds an event to the event queue. It is thread safe.
+	pushEvent(ev sdl.Event) error
+
+	// captureDevices returns the names of audio capture devices.
+	captureDevices() ([]string, error)
+
+	// openCapture opens a paused mono 16 bits little-endian audio capture
+	// device with the given name and desired sample rate, and returns
+	// the obtained sample rate.
+	openCapture(device string, rate int) (captureDevice, int, error)
+
+	// initControllers initializes game controller support.
+	initControllers() error
+
+	// openController opens the game controller with the given device
+	// index, so that it reports events.
+	openController(index int) error
+
+	// setHint sets a configuration hint.
+	setHint(name, value string) error
+
+	// lastError returns the last library error message, if any, and
+	// clears it.
+	lastError() error
+}
+
+// window represents the subset of the *sdl.Window methods used by the
+// driver.
+type window interface {
+	GetSize() (w, h int32)
+	SetSize(w, h int32)
+	SetTitle(title string)
+	SetIcon(icon *sdl.Surface)
+	SetFullscreen(flags uint32) error
+	SetResizable(resizable bool)
+	SetWindowOpacity(opacity float32) error
+	GetPosition() (x, y int32)
+	SetPosition(x, y int32)
+	Destroy() error
+}
+
+// renderer represents the rendering functionality used by the driver. Apart
+// from texture handling, it matches the *sdl.Renderer methods.
+type renderer interface {
+	SetScale(scaleX, scaleY float32) error
+	SetDrawColor(r, g, b, a uint8) error
+	SetDrawBlendMode(bm sdl.BlendMode) error
+	FillRect(rect *sdl.Rect) error
+	FillRects(rects []sdl.Rect) error
+	SetClipRect(rect *sdl.Rect) error
+	Clear() error
+	Present()
+	Destroy() error
+	GetInfo() (sdl.RendererInfo, error)
+
+	// createTexture returns a new texture from an image.
+	createTexture(img image.Image) (texture, error)
+
+	// stageTexture prepares an image for texture creation. Unlike other
+	// methods, it may be called from any goroutine.
+	stageTexture(img image.Image) (staged, error)
+
+	// uploadTexture returns a new texture from staged data, and releases
+	// the latter.
+	uploadTexture(st staged) (texture, error)
+
+	// setTextureFormats sets the pixel formats used for creating
+	// textures from opaque images and images with transparency. A zero
+	// format means the default 32 bits format.
+	setTextureFormats(opaque, alpha uint32)
+
+	// setLogicalSize sets a device independent resolution for
+	// rendering, optionally forcing integer scales, or disables it if
+	// the size is zero. Mouse event coordinates are then converted
+	// accordingly. It replaces SetScale.
+	setLogicalSize(w, h int32, integer bool) error
+
+	// createAtlas returns a new texture with the given size, with
+	// undefined content, that can be filled with updateTexture.
+	createAtlas(w, h int32) (texture, error)
+
+	// createStreaming returns a new texture with the given size, with
+	// undefined content, that is meant to be frequently updated with
+	// updateTexture.
+	createStreaming(w, h int32) (texture, error)
+
+	// updateTexture updates a region of a texture created by
+	// createAtlas or createStreaming with the given image, of the same
+	// size.
+	updateTexture(tx texture, rect sdl.Rect, img *image.NRGBA) error
+
+	// createTarget returns a new texture with the given size that can
+	// be used as rendering target.
+	createTarget(w, h int32) (texture, error)
+
+	// setTarget sets the current rendering target to a texture created
+	// by createTarget, or to the window if nil. The rendering scale only
+	// applies to the window.
+	setTarget(tx texture) error
+
+	// copy copies a portion of a texture to the current rendering target.
+	copy(tx texture, src, dst *sdl.Rect) error
+
+	// copyEx is like copy, but flips the texture, and then rotates it
+	// clockwise by angle degrees around the destination's center.
+	copyEx(tx texture, src, dst *sdl.Rect, angle float64, flip sdl.RendererFlip) error
+
+	// readPixels returns the content of the current rendering target.
+	readPixels() (*image.RGBA, error)
+}
+
+// texture represents a renderer texture, matching the *sdl.Texture methods
+// used by the driver.
+type texture interface {
+	SetAlphaMod(alpha uint8) error
+	SetColorMod(r, g, b uint8) error
+	SetBlendMode(bm sdl.BlendMode) error
+	Destroy() error
+}
+
+// staged represents an image prepared for texture creation.
+type staged interface {
+	// free releases the staged data.
+	free()
+}
+
+// sdlStaged implements staged with an SDL surface.
+type sdlStaged struct {
+	*sdl.Surface
+}
+
+func (st sdlStaged) free() {
+	st.Free()
+}
+
+// sdlBackend is the backend using the SDL library.
+type sdlBackend struct {
+	cursor *sdl.Cursor // custom cursor, if any
+}
+
+func (sdlBackend) init() error {
+	return sdl.Init(sdl.INIT_VIDEO)
+}
+
+func (b *sdlBackend) quit() {
+	if b.cursor != nil {
+		sdl.FreeCursor(b.cursor)
+		b.cursor = nil
+	}
+	sdl.Quit()
+}
+
+func (sdlBackend) createWindow(title string, w, h int32, flags uint32) (window, error) {
+	win, err := sdl.CreateWindow(title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED, w, h, flags)
+	if err != nil {
+		return nil, err
+	}
+	return win, nil
+}
+
+func (sdlBackend) createRenderer(win window, driver string, flags uint32) (renderer, error) {
+	index := -1
+	if driver != "" {
+		var err error
+		index, err = renderDriverIndex(driver)
+		if err != nil {
+			return nil, err
+		}
+	}
+	r, err := sdl.CreateRenderer(win.(*sdl.Window), index, flags)
+	if err != nil {
+		return nil, err
+	}
+	return &sdlRenderer{Renderer: r}, nil
+}
+
+func (sdlBackend) pollEvent() sdl.Event {
+	return sdl.PollEvent()
+}
+
+func (sdlBackend) waitEvent(timeout int) sdl.Event {
+	if timeout < 0 {
+		return sdl.WaitEvent()
+	}
+	return sdl.WaitEventTimeout(timeout)
+}
+
+func (sdlBackend) displayDPI(win window) (float32, error) {
+	idx, err := win.(*sdl.Window).GetDisplayIndex()
+	if err != nil {
+		return 0, err
+	}
+	ddpi, _, _, err := sdl.GetDisplayDPI(idx)
+	return ddpi, err
+}
+
+func (sdlBackend) refreshRate(win window) (int, error) {
+	idx, err := win.(*sdl.Window).GetDisplayIndex()
+	if err != nil {
+		return 0, err
+	}
+	mode, err := sdl.GetCurrentDisplayMode(idx)
+	return int(mode.RefreshRate), err
+}
+
+func (sdlBackend) modState() sdl.Keymod {
+	return sdl.GetModState()
+}
+
+func (sdlBackend) startTextInput() {
+	sdl.StartTextInput()
+	rect := sdl.Rect{X: 0, Y: 0, W: 100, H: 100}
+	sdl.SetTextInputRect(&rect)
+}
+
+func (sdlBackend) stopTextInput() {
+	sdl.StopTextInput()
+}
+
+func (sdlBackend) registerEvent() (uint32, error) {
+	typ := sdl.RegisterEvents(1)
+	if typ == math.MaxUint32 {
+		return 0, errors.New("no more user events available")
+	}
+	return typ, nil
+}
+
+func (sdlBackend) pushEvent(ev sdl.Event) error {
+	_, err := sdl.PushEvent(ev)
+	return err
+}
+
+func (sdlBackend) captureDevices() ([]string, error) {
+	err := sdl.InitSubSystem(sdl.INIT_AUDIO)
+	if err != nil {
+		return nil, err
+	}
+	defer sdl.QuitSubSystem(sdl.INIT_AUDIO)
+	n := sdl.GetNumAudioDevices(true)
+	names := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		names = append(names, sdl.GetAudioDeviceName(i, true))
+	}
+	return names, nil
+}
+
+func (sdlBackend) openCapture(device string, rate int) (captureDevice, int, error) {
+	err := sdl.InitSubSystem(sdl.INIT_AUDIO)
+	if err != nil {
+		return nil, 0, err
+	}
+	desired := sdl.AudioSpec{Freq: int32(rate), Format: sdl.AUDIO_S16LSB, Channels: 1, Samples: 1024}
+	var obtained sdl.AudioSpec
+	id, err := sdl.OpenAudioDevice(device, true, &desired, &obtained, sdl.AUDIO_ALLOW_FREQUENCY_CHANGE)
+	if err != nil {
+		sdl.QuitSubSystem(sdl.INIT_AUDIO)
+		return nil, 0, err
+	}
+	return sdlCapture(id), int(obtained.Freq), nil
+}
+
+// sdlCapture implements captureDevice with an SDL audio device.
+type sdlCapture sdl.AudioDeviceID
+
+func (c sdlCapture) queued() int {
+	return int(sdl.GetQueuedAudioSize(sdl.AudioDeviceID(c)))
+}
+
+func (c sdlCapture) dequeue(b []byte) error {
+	// SDL_DequeueAudio returns the number of dequeued bytes, which the
+	// bindings interpret as an error, so we rely on the error message.
+	sdl.ClearError()
+	sdl.DequeueAudio(sdl.AudioDeviceID(c), b)
+	return sdl.GetError()
+}
+
+func (c sdlCapture) pause(paused bool) {
+	sdl.PauseAudioDevice(sdl.AudioDeviceID(c), paused)
+}
+
+// close closes the device and releases the audio subsystem initialized by
+// openCapture.
+func (c sdlCapture) close() {
+	sdl.CloseAudioDevice(sdl.AudioDeviceID(c))
+	sdl.QuitSubSystem(sdl.INIT_AUDIO)
+}
+
+func (sdlBackend) initControllers() error {
+	return sdl.InitSubSystem(sdl.INIT_GAMECONTROLLER)
+}
+
+func (sdlBackend) openController(index int) error {
+	if !sdl.IsGameController(index) {
+		return nil
+	}
+	// Controllers are closed by sdl.Quit.
+	if sdl.GameControllerOpen(index) == nil {
+		return sdl.GetError()
+	}
+	return nil
+}
+
+func (sdlBackend) setHint(name, value string) error {
+	if !sdl.SetHint(name, value) {
+		return fmt.Errorf("hint %s: %v", name, sdl.GetError())
+	}
+	return nil
+}
+
+func (sdlBackend) lastError() error {
+	err := sdl.GetError()
+	sdl.ClearError()
+	return err
+}
+
+func (b *sdlBackend) setCursor(img image.Image, hot image.Point) error {
+	var cursor *sdl.Cursor
+	if img == nil {
+		sdl.SetCursor(sdl.GetDefaultCursor())
+	} else {
+		sf, err := imageToSurface(img)
+		if err != nil {
+			return err
+		}
+		defer sf.Free()
+		cursor = sdl.CreateColorCursor(sf, int32(hot.X), int32(hot.Y))
+		if cursor == nil {
+			return sdl.GetError()
+		}
+		sdl.SetCursor(cursor)
+	}
+	if b.cursor != nil {
+		sdl.FreeCursor(b.cursor)
+	}
+	b.cursor = cursor
+	return nil
+}
+
+func (b *sdlBackend) showCursor(show bool) error {
+	toggle := sdl.DISABLE
+	if show {
+		toggle = sdl.ENABLE
+	}
+	_, err := sdl.ShowCursor(toggle)
+	return err
+}
+
+// sdlRenderer implements renderer using an *sdl.Renderer.
+type sdlRenderer struct {
+	*sdl.Renderer
+	opaqueFormat uint32 // pixel format for opaque tiles, if not zero
+	alphaFormat  uint32 // pixel format for tiles with transparency, if not zero
+}
+
+func (r *sdlRenderer) setTextureFormats(opaque, alpha uint32) {
+	r.opaqueFormat, r.alphaFormat = opaque, alpha
+}
+
+// surface converts an image into a surface, using the configured texture
+// formats, if any.
+func (r *sdlRenderer) surface(img image.Image) (*sdl.Surface, error) {
+	sf, err := imageToSurface(img)
+	if err != nil || r.opaqueFormat == 0 && r.alphaFormat == 0 {
+		return sf, err
+	}
+	format := r.opaqueFormat
+	if !isOpaque(img) {
+		format = r.alphaFormat
+	}
+	if format == 0 {
+		return sf, nil
+	}
+	csf, err := sf.ConvertFormat(format, 0)
+	sf.Free()
+	return csf, err
+}
+
+func (r *sdlRenderer) createTexture(img image.Image) (texture, error) {
+	sf, err := r.surface(img)
+	if err != nil {
+		return nil, err
+	}
+	defer sf.Free()
+	tx, err := r.CreateTextureFromSurface(sf)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *sdlRenderer) stageTexture(img image.Image) (staged, error) {
+	sf, err := r.surface(img)
+	if err != nil {
+		return nil, err
+	}
+	return sdlStaged{sf}, nil
+}
+
+func (r *sdlRenderer) uploadTexture(st staged) (texture, error) {
+	defer st.free()
+	tx, err := r.CreateTextureFromSurface(st.(sdlStaged).Surface)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *sdlRenderer) setLogicalSize(w, h int32, integer bool) error {
+	err := r.SetIntegerScale(integer)
+	if err != nil {
+		return err
+	}
+	return r.SetLogicalSize(w, h)
+}
+
+func (r *sdlRenderer) createAtlas(w, h int32) (texture, error) {
+	tx, err := r.CreateTexture(uint32(sdl.PIXELFORMAT_RGBA32), sdl.TEXTUREACCESS_STATIC, w, h)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *sdlRenderer) createStreaming(w, h int32) (texture, error) {
+	tx, err := r.CreateTexture(uint32(sdl.PIXELFORMAT_RGBA32), sdl.TEXTUREACCESS_STREAMING, w, h)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *sdlRenderer) updateTexture(tx texture, rect sdl.Rect, img *image.NRGBA) error {
+	return tx.(*sdl.Texture).Update(&rect, img.Pix, img.Stride)
+}
+
+func (r *sdlRenderer) createTarget(w, h int32) (texture, error) {
+	tx, err := r.CreateTexture(uint32(sdl.PIXELFORMAT_RGBA8888), sdl.TEXTUREACCESS_TARGET, w, h)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *sdlRenderer) setTarget(tx texture) error {
+	if tx == nil {
+		return r.SetRenderTarget(nil)
+	}
+	return r.SetRenderTarget(tx.(*sdl.Texture))
+}
+
+func (r *sdlRenderer) copy(tx texture, src, dst *sdl.Rect) error {
+	return r.Copy(tx.(*sdl.Texture), src, dst)
+}
+
+func (r *sdlRenderer) copyEx(tx texture, src, dst *sdl.Rect, angle float64, flip sdl.RendererFlip) error {
+	return r.CopyEx(tx.(*sdl.Texture), src, dst, angle, nil, flip)
+}
+
+func (r *sdlRenderer) readPixels() (*image.RGBA, error) {
+	w, h, err := r.GetOutputSize()
+	if err != nil {
+		return nil, err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, int(w), int(h)))
+	if w == 0 || h == 0 {
+		return img, nil
+	}
+	err = r.ReadPixels(nil, uint32(sdl.PIXELFORMAT_RGBA32), unsafe.Pointer(&img.Pix[0]), img.Stride)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
@@ -0,0 +1,127 @@
+package sdl
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Gradient is an image of a linear gradient between two colors, that can be
+// used as background (see Config.Background).
+type Gradient struct {
+	From, To   color.Color
+	Horizontal bool // left to right gradient, instead of top to bottom
+}
+
+// gradientSize is the number of color steps in a Gradient image.
+const gradientSize = 256
+
+// ColorModel implements image.Image.
+func (g Gradient) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds implements image.Image.
+func (g Gradient) Bounds() image.Rectangle {
+	if g.Horizontal {
+		return image.Rect(0, 0, gradientSize, 1)
+	}
+	return image.Rect(0, 0, 1, gradientSize)
+}
+
+// At implements image.Image.
+func (g Gradient) At(x, y int) color.Color {
+	t := y
+	if g.Horizontal {
+		t = x
+	}
+	r0, g0, b0, a0 := g.From.RGBA()
+	r1, g1, b1, a1 := g.To.RGBA()
+	mix := func(u, v uint32) uint8 {
+		return uint8((int(u)*(gradientSize-1-t) + int(v)*t) / (gradientSize - 1) >> 8)
+	}
+	return color.RGBA{R: mix(r0, r1), G: mix(g0, g1), B: mix(b0, b1), A: mix(a0, a1)}
+}
+
+// SetBackground changes the background image drawn beneath cells (see
+// Config.Background). A nil image removes the background. If the driver is
+// already running, change will take effect with next Flush so that the
+// function is thread safe.
+func (dr *Driver) SetBackground(img image.Image) {
+	fn := func() {
+		dr.clearBackground()
+		dr.background = img
+		if dr.init {
+			select {
+			case dr.reqredraw <- true:
+			default:
+			}
+		}
+	}
+	if dr.init {
+		dr.queueSet("SetBackground", fn)
+	} else {
+		fn()
+	}
+}
+
+// clearBackground releases the background texture, if any.
+func (dr *Driver) clearBackground() {
+	if dr.bgTexture == nil {
+		return
+	}
+	err := dr.bgTexture.Destroy()
+	if err != nil {
+		dr.logf("background destroy: %v", err)
+	}
+	dr.bgTexture = nil
+}
+
+// clearCell clears a cell's rectangle before drawing a translucent tile,
+// drawing the matching part of the background image, if any, or filling it
+// with the background color otherwise.
+func (dr *Driver) clearCell(rect *sdl.Rect) error {
+	if dr.background != nil {
+		err := dr.drawBackground(rect)
+		if err == nil {
+			return nil
+		}
+		dr.logf("background: %v", err)
+	}
+	r, g, b := rgb(dr.bgColor)
+	err := dr.renderer.SetDrawColor(r, g, b, 255)
+	if err == nil {
+		err = dr.renderer.FillRect(rect)
+	}
+	return err
+}
+
+// drawBackground draws the part of the background image matching a
+// rectangle. The background image is stretched over the whole grid.
+func (dr *Driver) drawBackground(rect *sdl.Rect) error {
+	if dr.bgTexture == nil {
+		tx, err := dr.renderer.createTexture(dr.background)
+		if err != nil {
+			return err
+		}
+		dr.bgTexture = tx
+	}
+	b := dr.background.Bounds()
+	gr := dr.gridRect()
+	w, h := float32(gr.W), float32(gr.H)
+	sx, sy := float32(b.Dx())/w, float32(b.Dy())/h
+	src := sdl.Rect{
+		X: int32(float32(rect.X-gr.X) * sx),
+		Y: int32(float32(rect.Y-gr.Y) * sy),
+		W: int32(float32(rect.W)*sx + 0.5),
+		H: int32(float32(rect.H)*sy + 0.5),
+	}
+	if src.W < 1 {
+		src.W = 1
+	}
+	if src.H < 1 {
+		src.H = 1
+	}
+	return dr.renderer.copy(dr.bgTexture, &src, rect)
+}
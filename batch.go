@@ -0,0 +1,227 @@
+package sdl
+
+import (
+	"fmt"
+	"image"
+	"sync"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TileManagerBatch is an optional extension of TileManager for tile managers
+// that can generate many tiles more efficiently at once, for example by
+// amortizing font or atlas setup costs. When implemented, the driver uses
+// GetImages to fill all the cache misses of a frame at once, before drawing.
+type TileManagerBatch interface {
+	TileManager
+
+	// GetImages returns the images to be used for the given cells, in the
+	// same order. A nil image means that no tile could be provided: the
+	// driver then falls back to GetImage (or GetImageErr) for that cell.
+	GetImages([]gruid.Cell) []image.Image
+}
+
+// prefetch fills the texture cache with the tiles for the cells of a frame
+// that are missing from it, using the TileManager's GetImages method if
+// available, or tile workers if configured (see Config.TileWorkers). If
+// full is true, the tiles for the whole grid are prefetched.
+func (dr *Driver) prefetch(frame gruid.Frame, full bool) {
+	tm, batch := dr.tm.(TileManagerBatch)
+	if !batch && dr.tileWorkers <= 1 {
+		return
+	}
+	glyph := dr.glyphColors != nil
+	misses := dr.misses[:0]
+	seen := make(map[gruid.Cell]bool)
+	add := func(c gruid.Cell) {
+		k := dr.key(c)
+		if _, ok := dr.textures[k]; ok || seen[k] {
+			return
+		}
+		seen[k] = true
+		misses = append(misses, c)
+	}
+	for _, fc := range frame.Cells {
+		add(fc.Cell)
+	}
+	if full {
+		for _, c := range dr.grid {
+			add(c)
+		}
+	}
+	dr.misses = misses
+	if len(misses) < 2 {
+		return
+	}
+	cells := misses
+	if dr.ghostAttr != 0 {
+		cells = make([]gruid.Cell, len(misses))
+		for i, c := range misses {
+			c.Style.Attrs &^= dr.ghostAttr
+			cells[i] = c
+		}
+	}
+	if !batch {
+		dr.pipeline(misses, cells, nil)
+		return
+	}
+	if glyph {
+		// Glyph masks are made from tiles with default colors.
+		gcells := make([]gruid.Cell, len(cells))
+		for i, c := range cells {
+			gcells[i] = glyphCell(c)
+		}
+		cells = gcells
+	}
+	imgs, err := dr.getImages(tm, cells)
+	if err != nil {
+		dr.handleError(err)
+		return
+	}
+	if len(imgs) > len(misses) {
+		imgs = imgs[:len(misses)]
+	}
+	if dr.tileWorkers > 1 {
+		dr.pipeline(misses, cells, imgs)
+		return
+	}
+	for i, img := range imgs {
+		if img == nil {
+			continue
+		}
+		if glyph {
+			img = glyphMask(img)
+		}
+		tx, err := dr.newTexture(img)
+		if err != nil {
+			dr.addTextureFailure()
+			dr.handleError(fmt.Errorf("prefetch: texture: %v", err))
+			continue
+		}
+		dr.addTexture(misses[i], tx, img.Bounds().Size())
+	}
+}
+
+// addTexture adds a texture for a cell to the cache, given the size of its
+// image.
+func (dr *Driver) addTexture(c gruid.Cell, tx texture, size image.Point) {
+	if dr.glyphColors != nil {
+		if err := tx.SetBlendMode(sdl.BLENDMODE_BLEND); err != nil {
+			tx.Destroy()
+			dr.handleError(fmt.Errorf("prefetch: glyph texture: %v", err))
+			return
+		}
+	}
+	if c.Style.Attrs&dr.ghostAttr != 0 {
+		if err := dr.ghostTexture(tx); err != nil {
+			tx.Destroy()
+			dr.handleError(err)
+			return
+		}
+	}
+	key := dr.key(c)
+	dr.storeTexture(key, tx)
+	dr.setTileSize(key, size)
+	dr.cache.Misses++
+	dr.touch(key)
+}
+
+// getImages calls the TileManager's GetImages method, recovering from panics
+// if configured to do so.
+func (dr *Driver) getImages(tm TileManagerBatch, cells []gruid.Cell) (imgs []image.Image, err error) {
+	if dr.recover {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("prefetch: panic: %v", r)
+			}
+		}()
+	}
+	return tm.GetImages(cells), nil
+}
+
+// stagedTile is a tile prepared by a tile worker.
+type stagedTile struct {
+	i    int // index of the tile's cell
+	st   staged
+	size image.Point // size of the tile's image
+	err  error
+}
+
+// pipeline creates textures for the given cache misses, using the given
+// images, or the TileManager if imgs is nil. Image generation and surface
+// conversion are done by tile workers, while textures are created on the
+// main routine as soon as tiles are staged, so that both overlap. Tiles that
+// could not be staged are left for the draw step, which reports errors.
+func (dr *Driver) pipeline(misses, cells []gruid.Cell, imgs []image.Image) {
+	jobs := make(chan int)
+	done := make(chan stagedTile, dr.tileWorkers)
+	var wg sync.WaitGroup
+	for w := 0; w < dr.tileWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				var img image.Image
+				if imgs != nil {
+					img = imgs[i]
+				}
+				done <- dr.stageTile(i, cells[i], img)
+			}
+		}()
+	}
+	go func() {
+		n := len(cells)
+		if imgs != nil {
+			n = len(imgs)
+		}
+		for i := 0; i < n; i++ {
+			if imgs != nil && imgs[i] == nil {
+				continue
+			}
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+	for st := range done {
+		if st.err != nil {
+			continue
+		}
+		tx, err := dr.uploadTile(st.st)
+		if err != nil {
+			dr.addTextureFailure()
+			dr.handleError(fmt.Errorf("prefetch: texture: %v", err))
+			continue
+		}
+		dr.addTexture(misses[st.i], tx, st.size)
+	}
+}
+
+// stageTile prepares the tile for a cell, using the given image if not nil.
+// It is called from tile workers.
+func (dr *Driver) stageTile(i int, c gruid.Cell, img image.Image) (st stagedTile) {
+	st.i = i
+	if dr.recover {
+		defer func() {
+			if r := recover(); r != nil {
+				st.err = fmt.Errorf("prefetch: panic for %+v: %v", c, r)
+			}
+		}()
+	}
+	switch {
+	case img == nil && dr.glyphColors != nil:
+		img, st.err = dr.getGlyph(c)
+	case img == nil:
+		img, st.err = dr.getImage(c)
+	case dr.glyphColors != nil:
+		img = glyphMask(img)
+	}
+	if st.err != nil {
+		return st
+	}
+	st.size = img.Bounds().Size()
+	st.st, st.err = dr.stageImage(img)
+	return st
+}
@@ -0,0 +1,201 @@
+// Package bench provides a benchmark harness for the sdl driver: it drives a
+// driver with generated frames and reports throughput and allocation
+// statistics, so that rendering optimizations can be measured and regressions
+// caught.
+//
+// A typical use, from a main function or a Go benchmark, is:
+//
+//	dr := sdl.NewDriver(sdl.Config{TileManager: tm, Headless: true})
+//	if err := dr.Init(); err != nil {
+//		log.Fatal(err)
+//	}
+//	defer dr.Close()
+//	for _, sc := range bench.Scenarios {
+//		fmt.Println(bench.Run(dr, sc, 1000))
+//	}
+package bench
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// Driver is the part of the driver's interface used by the benchmarks. It is
+// implemented by *sdl.Driver.
+type Driver interface {
+	Flush(gruid.Frame)
+}
+
+// Scenario describes a kind of synthetic frames.
+type Scenario struct {
+	Name string // scenario name
+
+	// Frame returns the i-th frame for a grid of the given size. The
+	// returned frame's cells may be reused by the next call.
+	Frame func(i int, size gruid.Point) gruid.Frame
+}
+
+// Scenarios contains the default scenarios: full-screen changes, sparse
+// deltas, and full-screen changes with high color variety.
+var Scenarios = []Scenario{
+	{Name: "full", Frame: FullFrames(8)},
+	{Name: "sparse", Frame: SparseFrames(16)},
+	{Name: "colorful", Frame: ColorfulFrames(4096)},
+}
+
+// Result contains the results of running a scenario.
+type Result struct {
+	Scenario    string        // scenario name
+	Frames      int           // number of flushed frames
+	Cells       int           // total number of cells in flushed frames
+	Duration    time.Duration // total duration
+	Allocs      uint64        // total number of heap allocations
+	AllocBytes  uint64        // total bytes allocated on the heap
+	FrameTime   time.Duration // average duration of a frame
+	CellsPerSec float64       // flushed cells per second
+}
+
+// String returns a one-line summary of the results.
+func (r Result) String() string {
+	allocs, bytes := uint64(0), uint64(0)
+	if r.Frames > 0 {
+		allocs = r.Allocs / uint64(r.Frames)
+		bytes = r.AllocBytes / uint64(r.Frames)
+	}
+	return fmt.Sprintf("%-10s %6d frames  %10v/frame  %12.0f cells/s  %6d allocs/frame  %8d B/frame",
+		r.Scenario, r.Frames, r.FrameTime, r.CellsPerSec, allocs, bytes)
+}
+
+// Run flushes n frames generated by the given scenario for a grid of size 80x24
+// and returns the results.
+func Run(dr Driver, sc Scenario, n int) Result {
+	return RunSize(dr, sc, n, gruid.Point{X: 80, Y: 24})
+}
+
+// RunSize is like Run, but with a custom grid size.
+func RunSize(dr Driver, sc Scenario, n int, size gruid.Point) Result {
+	res := Result{Scenario: sc.Name, Frames: n}
+	// Flush a first frame, so that the window is resized before
+	// measuring.
+	dr.Flush(sc.Frame(0, size))
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 1; i <= n; i++ {
+		frame := sc.Frame(i, size)
+		res.Cells += len(frame.Cells)
+		dr.Flush(frame)
+	}
+	res.Duration = time.Since(start)
+	runtime.ReadMemStats(&after)
+	res.Allocs = after.Mallocs - before.Mallocs
+	res.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	if n > 0 {
+		res.FrameTime = res.Duration / time.Duration(n)
+	}
+	if res.Duration > 0 {
+		res.CellsPerSec = float64(res.Cells) / res.Duration.Seconds()
+	}
+	return res
+}
+
+// FullFrames returns a scenario function generating frames where all the
+// cells change, using the given number of distinct cells.
+func FullFrames(variety int) func(int, gruid.Point) gruid.Frame {
+	var cells []gruid.FrameCell
+	return func(i int, size gruid.Point) gruid.Frame {
+		cells = cells[:0]
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				k := (x + y + i) % variety
+				cells = append(cells, gruid.FrameCell{P: gruid.Point{X: x, Y: y}, Cell: synthCell(k)})
+			}
+		}
+		return gruid.Frame{Cells: cells, Width: size.X, Height: size.Y, Time: time.Now()}
+	}
+}
+
+// SparseFrames returns a scenario function generating frames where only the
+// given number of random cells change.
+func SparseFrames(changes int) func(int, gruid.Point) gruid.Frame {
+	var cells []gruid.FrameCell
+	rd := rand.New(rand.NewSource(1))
+	return func(i int, size gruid.Point) gruid.Frame {
+		if i == 0 {
+			return FullFrames(1)(0, size)
+		}
+		cells = cells[:0]
+		for j := 0; j < changes; j++ {
+			p := gruid.Point{X: rd.Intn(size.X), Y: rd.Intn(size.Y)}
+			cells = append(cells, gruid.FrameCell{P: p, Cell: synthCell(rd.Intn(64))})
+		}
+		return gruid.Frame{Cells: cells, Width: size.X, Height: size.Y, Time: time.Now()}
+	}
+}
+
+// ColorfulFrames is like FullFrames, but cells use random colors out of the
+// given number of colors, stressing the texture cache.
+func ColorfulFrames(colors int) func(int, gruid.Point) gruid.Frame {
+	var cells []gruid.FrameCell
+	rd := rand.New(rand.NewSource(1))
+	return func(i int, size gruid.Point) gruid.Frame {
+		cells = cells[:0]
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				c := gruid.Cell{Rune: rune('a' + rd.Intn(26))}
+				c.Style.Fg = gruid.Color(1 + rd.Intn(colors))
+				c.Style.Bg = gruid.Color(1 + rd.Intn(colors))
+				cells = append(cells, gruid.FrameCell{P: gruid.Point{X: x, Y: y}, Cell: c})
+			}
+		}
+		return gruid.Frame{Cells: cells, Width: size.X, Height: size.Y, Time: time.Now()}
+	}
+}
+
+func synthCell(k int) gruid.Cell {
+	c := gruid.Cell{Rune: rune('!' + k%94)}
+	c.Style.Fg = gruid.Color(1 + k%16)
+	c.Style.Bg = gruid.Color(1 + (k/16)%16)
+	return c
+}
+
+// TileManager is a simple TileManager drawing tiles as two colored
+// rectangles, for benchmarking without depending on font rendering. Its
+// GetImage method allocates a new image for each call, like typical
+// TileManagers.
+type TileManager struct {
+	Size gruid.Point // tile size (default: 16x24)
+}
+
+// GetImage implements sdl.TileManager.GetImage.
+func (tm TileManager) GetImage(c gruid.Cell) image.Image {
+	size := tm.TileSize()
+	img := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	draw.Draw(img, img.Rect, image.NewUniform(synthColor(c.Style.Bg)), image.Point{}, draw.Src)
+	fg := image.Rect(size.X/4, size.Y/4, 3*size.X/4, 3*size.Y/4)
+	if c.Rune != ' ' {
+		draw.Draw(img, fg, image.NewUniform(synthColor(c.Style.Fg)), image.Point{}, draw.Src)
+	}
+	return img
+}
+
+// TileSize implements sdl.TileManager.TileSize.
+func (tm TileManager) TileSize() gruid.Point {
+	if tm.Size.X <= 0 || tm.Size.Y <= 0 {
+		return gruid.Point{X: 16, Y: 24}
+	}
+	return tm.Size
+}
+
+func synthColor(c gruid.Color) color.RGBA {
+	x := uint32(c) * 2654435761
+	return color.RGBA{R: uint8(x >> 24), G: uint8(x >> 16), B: uint8(x >> 8), A: 0xff}
+}
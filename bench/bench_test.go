@@ -0,0 +1,42 @@
+package bench_test
+
+import (
+	"testing"
+
+	sdl "github.com/anaseto/gruid-sdl"
+	"github.com/anaseto/gruid-sdl/bench"
+)
+
+func newDriver(tb testing.TB) *sdl.Driver {
+	dr := sdl.NewDriver(sdl.Config{TileManager: bench.TileManager{}, Headless: true})
+	if err := dr.Init(); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(dr.Close)
+	return dr
+}
+
+func TestRun(t *testing.T) {
+	for _, sc := range bench.Scenarios {
+		res := bench.Run(newDriver(t), sc, 3)
+		if res.Scenario != sc.Name || res.Frames != 3 {
+			t.Errorf("%s: bad result: %v", sc.Name, res)
+		}
+		if res.Cells == 0 {
+			t.Errorf("%s: no drawn cells", sc.Name)
+		}
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	for _, sc := range bench.Scenarios {
+		sc := sc
+		b.Run(sc.Name, func(b *testing.B) {
+			dr := newDriver(b)
+			b.ReportAllocs()
+			b.ResetTimer()
+			res := bench.Run(dr, sc, b.N)
+			b.ReportMetric(res.CellsPerSec, "cells/s")
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package sdl
+
+import (
+	"container/list"
+
+	"github.com/anaseto/gruid"
+)
+
+// cacheLRU tracks the use order of cached tile textures, for eviction when
+// the cache is limited (see Config.CacheLimit and Config.CacheMemoryLimit).
+type cacheLRU struct {
+	order *list.List // cache keys, most recently used first
+	elems map[gruid.Cell]*list.Element
+}
+
+// limitedCache reports whether the texture cache has a size limit.
+func (dr *Driver) limitedCache() bool {
+	return dr.cacheLimit > 0 || dr.cacheMemoryLimit > 0
+}
+
+// numCommon is the number of runes whose textures for the default style are
+// also stored in an array, avoiding map lookups for the most common cells.
+const numCommon = 128
+
+// commonIndex returns the index of a cache key in the array of common cell
+// textures, and whether the key is a common cell.
+func commonIndex(key gruid.Cell) (int, bool) {
+	if key.Style != (gruid.Style{}) || key.Rune < 0 || key.Rune >= numCommon {
+		return 0, false
+	}
+	return int(key.Rune), true
+}
+
+// lookupTexture returns the cached texture with the given key, if any.
+func (dr *Driver) lookupTexture(key gruid.Cell) (texture, bool) {
+	if i, ok := commonIndex(key); ok {
+		tx := dr.common[i]
+		return tx, tx != nil
+	}
+	tx, ok := dr.textures[key]
+	return tx, ok
+}
+
+// storeTexture adds a texture to the cache with the given key.
+func (dr *Driver) storeTexture(key gruid.Cell, tx texture) {
+	dr.textures[key] = tx
+	if i, ok := commonIndex(key); ok {
+		dr.common[i] = tx
+	}
+}
+
+// deleteTexture removes the texture with the given key from the cache,
+// without destroying it.
+func (dr *Driver) deleteTexture(key gruid.Cell) {
+	delete(dr.textures, key)
+	if i, ok := commonIndex(key); ok {
+		dr.common[i] = nil
+	}
+}
+
+// touch records the use of the cached texture with the given key.
+func (dr *Driver) touch(key gruid.Cell) {
+	if !dr.limitedCache() {
+		return
+	}
+	lru := &dr.lru
+	if lru.order == nil {
+		lru.order = list.New()
+		lru.elems = make(map[gruid.Cell]*list.Element)
+	}
+	if e, ok := lru.elems[key]; ok {
+		lru.order.MoveToFront(e)
+		return
+	}
+	lru.elems[key] = lru.order.PushFront(key)
+}
+
+// tileBytes returns the estimated memory used by a tile texture.
+func (dr *Driver) tileBytes() int {
+	return int(dr.tw) * int(dr.th) * 4
+}
+
+// overLimit reports whether the cache exceeds its limits with n entries.
+func (dr *Driver) overLimit(n int) bool {
+	return dr.cacheLimit > 0 && n > dr.cacheLimit ||
+		dr.cacheMemoryLimit > 0 && n*dr.tileBytes() > dr.cacheMemoryLimit
+}
+
+// evict destroys the least recently used textures until the cache is within
+// its limits. It is called after drawing, so that textures used by a frame
+// are not evicted while drawing it.
+func (dr *Driver) evict() {
+	if !dr.limitedCache() || dr.lru.order == nil {
+		return
+	}
+	if dr.atlas {
+		if dr.overLimit(len(dr.textures)) {
+			dr.cache.Evictions += int64(len(dr.textures))
+			dr.ClearCache()
+		}
+		return
+	}
+	lru := &dr.lru
+	for dr.overLimit(len(dr.textures)) {
+		e := lru.order.Back()
+		if e == nil {
+			break
+		}
+		key := lru.order.Remove(e).(gruid.Cell)
+		delete(lru.elems, key)
+		if tx, ok := dr.textures[key]; ok {
+			dr.releaseTexture(tx)
+			dr.deleteTexture(key)
+			delete(dr.oversized, key)
+			dr.cache.Evictions++
+		}
+	}
+}
+
+// updateCacheStats publishes current cache statistics.
+func (dr *Driver) updateCacheStats() {
+	cs := dr.cache
+	cs.Entries = len(dr.textures)
+	cs.Memory = cs.Entries * dr.tileBytes()
+	if dr.atlas {
+		cs.Memory = 0
+		for _, p := range dr.atlasPages {
+			cs.Memory += int(p.w) * int(p.h) * 4
+		}
+	}
+	dr.stats.setCache(cs)
+}
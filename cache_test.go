@@ -0,0 +1,54 @@
+package sdl
+
+import (
+	"testing"
+
+	"github.com/anaseto/gruid"
+)
+
+func TestCacheLRU(t *testing.T) {
+	// The steps are run in order with the same driver, with a cache
+	// limited to three textures. Only changed cells are drawn, so that
+	// unchanged ones become less recently used.
+	tests := []struct {
+		frame  string // new content of the first row
+		cached string // expected cached runes
+	}{
+		{"abc", "abc"},
+		{"abd", "bcd"},  // a is the least recently drawn
+		{"abce", "cde"}, // only c and e are drawn
+		{"bbce", "bce"}, // b is drawn again
+		{"fbce", "bef"},
+	}
+	dr, _ := newTestDriver(t, Config{CacheLimit: 3})
+	for i, tt := range tests {
+		dr.Flush(testFrame(10, 5, tt.frame))
+		if n := len(dr.textures); n != len(tt.cached) {
+			t.Errorf("step %d: %d cached textures, want %d", i, n, len(tt.cached))
+		}
+		for _, r := range tt.cached {
+			if _, ok := dr.lookupTexture(gruid.Cell{Rune: r}); !ok {
+				t.Errorf("step %d: %c not cached", i, r)
+			}
+		}
+		if len(dr.lru.elems) != len(dr.textures) {
+			t.Errorf("step %d: %d LRU entries for %d textures", i, len(dr.lru.elems), len(dr.textures))
+		}
+	}
+	if dr.cache.Evictions != 4 {
+		t.Errorf("%d evictions, want 4", dr.cache.Evictions)
+	}
+}
+
+func TestCacheMemoryLimit(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{CacheMemoryLimit: 2 * testTileSize * testTileSize * 4})
+	dr.Flush(testFrame(10, 5, "abcd"))
+	if n := len(dr.textures); n != 2 {
+		t.Errorf("%d cached textures, want 2", n)
+	}
+	for _, r := range "cd" {
+		if _, ok := dr.lookupTexture(gruid.Cell{Rune: r}); !ok {
+			t.Errorf("%c not cached", r)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package sdl
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CaptureConfig contains configuration options for audio capture.
+type CaptureConfig struct {
+	Device string // capture device name (default: system's default device)
+	Rate   int    // sample rate in Hz (default: 44100)
+}
+
+// Capture represents an open audio capture device, such as a microphone.
+// Captured audio is mono 16 bits PCM, buffered by SDL until read. Its methods
+// may be called from any goroutine, but not concurrently.
+type Capture struct {
+	dev    captureDevice
+	rate   int
+	buf    []byte
+	closed bool
+}
+
+// captureDevice represents the backend functionality for audio capture.
+type captureDevice interface {
+	// queued returns the number of bytes of captured audio waiting to be
+	// read.
+	queued() int
+
+	// dequeue reads captured audio into b.
+	dequeue(b []byte) error
+
+	// pause pauses or resumes capture.
+	pause(paused bool)
+
+	// close closes the device.
+	close()
+}
+
+// CaptureDevices returns the names of available audio capture devices. It
+// should be called after Init.
+func (dr *Driver) CaptureDevices() ([]string, error) {
+	if !dr.init {
+		return nil, errors.New("capture: driver not initialized")
+	}
+	return dr.backend.captureDevices()
+}
+
+// OpenCapture opens an audio capture device and starts capturing, enabling
+// mechanics such as blowing out candles or push-to-talk. It should be called
+// after Init. The device should be closed with Close when no longer needed.
+func (dr *Driver) OpenCapture(cfg CaptureConfig) (*Capture, error) {
+	if !dr.init {
+		return nil, errors.New("capture: driver not initialized")
+	}
+	if cfg.Rate <= 0 {
+		cfg.Rate = 44100
+	}
+	dev, rate, err := dr.backend.openCapture(cfg.Device, cfg.Rate)
+	if err != nil {
+		return nil, fmt.Errorf("capture: %v", err)
+	}
+	dev.pause(false)
+	return &Capture{dev: dev, rate: rate}, nil
+}
+
+// Rate returns the actual sample rate of the device.
+func (c *Capture) Rate() int {
+	return c.rate
+}
+
+// Read reads captured samples into buf, and returns the number of samples
+// read, without blocking. Samples not read remain buffered.
+func (c *Capture) Read(buf []int16) (int, error) {
+	n := c.dev.queued() / 2
+	if n > len(buf) {
+		n = len(buf)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if cap(c.buf) < 2*n {
+		c.buf = make([]byte, 2*n)
+	}
+	b := c.buf[:2*n]
+	err := c.dev.dequeue(b)
+	if err != nil {
+		return 0, fmt.Errorf("capture: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		buf[i] = int16(binary.LittleEndian.Uint16(b[2*i:]))
+	}
+	return n, nil
+}
+
+// Level reads all the buffered samples and returns their amplitude level, as
+// a root mean square value between 0 (silence) and 1. It returns zero if no
+// samples were captured since last read.
+func (c *Capture) Level() (float64, error) {
+	var buf [1024]int16
+	var sum float64
+	var count int
+	for {
+		n, err := c.Read(buf[:])
+		if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			break
+		}
+		for _, s := range buf[:n] {
+			v := float64(s) / 32768
+			sum += v * v
+		}
+		count += n
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return math.Sqrt(sum / float64(count)), nil
+}
+
+// Pause pauses or resumes capture, for example for push-to-talk.
+func (c *Capture) Pause(paused bool) {
+	c.dev.pause(paused)
+}
+
+// Close stops capture and closes the device. Subsequent calls do nothing.
+func (c *Capture) Close() {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.dev.close()
+}
@@ -0,0 +1,149 @@
+package sdl
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// fakeCapture implements captureDevice with samples given in advance.
+type fakeCapture struct {
+	data   []byte
+	paused bool
+	closed int
+}
+
+func (c *fakeCapture) queued() int { return len(c.data) }
+
+func (c *fakeCapture) dequeue(b []byte) error {
+	n := copy(b, c.data)
+	c.data = c.data[n:]
+	return nil
+}
+
+func (c *fakeCapture) pause(paused bool) { c.paused = paused }
+
+func (c *fakeCapture) close() { c.closed++ }
+
+func newFakeCapture(samples ...int16) *fakeCapture {
+	c := &fakeCapture{}
+	for _, s := range samples {
+		c.data = append(c.data, byte(s), byte(uint16(s)>>8))
+	}
+	return c
+}
+
+func TestCaptureRead(t *testing.T) {
+	dev := newFakeCapture(1, -2, 3, 32767, -32768)
+	c := &Capture{dev: dev, rate: 44100}
+	buf := make([]int16, 3)
+	n, err := c.Read(buf)
+	if err != nil || n != 3 || buf[0] != 1 || buf[1] != -2 || buf[2] != 3 {
+		t.Errorf("Read = %d, %v, samples %v", n, err, buf)
+	}
+	n, err = c.Read(buf)
+	if err != nil || n != 2 || buf[0] != 32767 || buf[1] != -32768 {
+		t.Errorf("second Read = %d, %v, samples %v", n, err, buf[:n])
+	}
+	if n, err := c.Read(buf); n != 0 || err != nil {
+		t.Errorf("Read without samples = %d, %v", n, err)
+	}
+}
+
+func TestCaptureLevel(t *testing.T) {
+	tests := []struct {
+		samples []int16
+		want    float64
+	}{
+		{nil, 0},
+		{[]int16{0, 0, 0}, 0},
+		{[]int16{16384, -16384}, 0.5},
+		{make([]int16, 3000), 0},
+	}
+	for _, tt := range tests {
+		c := &Capture{dev: newFakeCapture(tt.samples...)}
+		level, err := c.Level()
+		if err != nil || math.Abs(level-tt.want) > 1e-9 {
+			t.Errorf("%d samples: level %v, %v, want %v", len(tt.samples), level, err, tt.want)
+		}
+		if n := c.dev.queued(); n != 0 {
+			t.Errorf("%d samples: %d bytes left", len(tt.samples), n)
+		}
+	}
+}
+
+func TestCaptureClose(t *testing.T) {
+	dev := newFakeCapture()
+	c := &Capture{dev: dev}
+	c.Pause(true)
+	if !dev.paused {
+		t.Error("device not paused")
+	}
+	c.Close()
+	c.Close()
+	if dev.closed != 1 {
+		t.Errorf("device closed %d times, want 1", dev.closed)
+	}
+}
+
+// TestCaptureSubSystem checks that the SDL backend releases the audio
+// subsystem it initializes, using SDL's disk audio driver, which reads
+// captured audio from a file.
+func TestCaptureSubSystem(t *testing.T) {
+	in := filepath.Join(t.TempDir(), "in.raw")
+	data := make([]byte, 4096)
+	for i := 0; i < len(data); i += 2 {
+		binary.LittleEndian.PutUint16(data[i:], 1000)
+	}
+	if err := ioutil.WriteFile(in, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{"SDL_AUDIODRIVER": "disk", "SDL_DISKAUDIOFILEIN": in, "SDL_DISKAUDIODELAY": "0"} {
+		old, ok := os.LookupEnv(k)
+		os.Setenv(k, v)
+		if ok {
+			defer os.Setenv(k, old)
+		} else {
+			defer os.Unsetenv(k)
+		}
+	}
+	if err := sdl.InitSubSystem(sdl.INIT_AUDIO); err != nil {
+		t.Skipf("no disk audio driver: %v", err)
+	}
+	sdl.QuitSubSystem(sdl.INIT_AUDIO)
+	defer sdl.Quit()
+	initialized := func() bool {
+		return sdl.WasInit(sdl.INIT_AUDIO) != 0
+	}
+	var b sdlBackend
+	if _, err := b.captureDevices(); err != nil {
+		t.Fatal(err)
+	}
+	if initialized() {
+		t.Error("audio initialized after captureDevices")
+	}
+	if _, _, err := b.openCapture("no such device", 44100); err == nil {
+		t.Error("no error for unknown device")
+	}
+	if initialized() {
+		t.Error("audio initialized after failed openCapture")
+	}
+	dev, _, err := b.openCapture("", 44100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Capture{dev: dev}
+	if !initialized() {
+		t.Error("audio not initialized while capturing")
+	}
+	c.Close()
+	c.Close()
+	if initialized() {
+		t.Error("audio initialized after closing capture")
+	}
+}
@@ -0,0 +1,150 @@
+package sdl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// cast represents a running asciicast recording.
+type cast struct {
+	w     *bufio.Writer
+	start time.Time
+	err   error
+}
+
+// StartCast starts recording flushed frames as an asciicast v2 stream into
+// the given writer, so that the session can be replayed with asciinema or
+// embedded on web pages. Cells are written as text with ANSI escape
+// sequences: colors are interpreted as in gruid's terminal drivers, that is,
+// non-default colors c are mapped to the 256-color palette entry c-1, and
+// larger values are interpreted as 24-bit RGB colors. Attributes are not
+// recorded. With Config.WideRunes, cells covered by a wide rune are not
+// written, as terminals draw wide runes over two columns. It should be
+// called from the main routine.
+//
+// It is your responsibility to close the writer after calling StopCast.
+func (dr *Driver) StartCast(w io.Writer, title string) error {
+	if dr.cast != nil {
+		return fmt.Errorf("cast: already started")
+	}
+	c := &cast{w: bufio.NewWriter(w), start: time.Now()}
+	header := struct {
+		Version   int    `json:"version"`
+		Width     int32  `json:"width"`
+		Height    int32  `json:"height"`
+		Timestamp int64  `json:"timestamp"`
+		Title     string `json:"title,omitempty"`
+	}{2, dr.width, dr.height, c.start.Unix(), title}
+	b, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("cast: %v", err)
+	}
+	c.w.Write(b)
+	c.w.WriteByte('\n')
+	dr.cast = c
+	if len(dr.grid) > 0 {
+		frame := dr.fullFrame(gruid.Frame{Width: int(dr.width), Height: int(dr.height)})
+		dr.castFrame(frame)
+	}
+	return c.err
+}
+
+// StopCast stops the current asciicast recording, if any, and flushes the
+// remaining data to the writer. It is called automatically by Close.
+func (dr *Driver) StopCast() error {
+	c := dr.cast
+	if c == nil {
+		return nil
+	}
+	dr.cast = nil
+	if c.err != nil {
+		return c.err
+	}
+	if err := c.w.Flush(); err != nil {
+		return fmt.Errorf("cast: %v", err)
+	}
+	return nil
+}
+
+// castFrame records a flushed frame, if an asciicast recording is running.
+func (dr *Driver) castFrame(frame gruid.Frame) {
+	c := dr.cast
+	if c == nil || c.err != nil || len(frame.Cells) == 0 {
+		return
+	}
+	t := frame.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	var sb strings.Builder
+	if len(frame.Cells) == frame.Width*frame.Height {
+		sb.WriteString("\x1b[2J")
+	}
+	cur := gruid.Point{X: -1, Y: -1}
+	var style gruid.Style
+	sb.WriteString("\x1b[0m")
+	for _, fc := range frame.Cells {
+		wide := false
+		if i := fc.P.X + int(dr.width)*fc.P.Y; dr.wideRunes && i >= 0 && i < len(dr.grid) {
+			if dr.covered(i) {
+				// Right half of a wide rune.
+				continue
+			}
+			wide = dr.wideAt(i)
+		}
+		if fc.P != cur {
+			fmt.Fprintf(&sb, "\x1b[%d;%dH", fc.P.Y+1, fc.P.X+1)
+		}
+		if fc.Cell.Style.Fg != style.Fg || fc.Cell.Style.Bg != style.Bg {
+			sb.WriteString("\x1b[0")
+			sb.WriteString(sgrColor(fc.Cell.Style.Fg, 38))
+			sb.WriteString(sgrColor(fc.Cell.Style.Bg, 48))
+			sb.WriteByte('m')
+			style = fc.Cell.Style
+		}
+		r := fc.Cell.Rune
+		if r < ' ' {
+			r = ' '
+		}
+		sb.WriteRune(r)
+		cur = gruid.Point{X: fc.P.X + 1, Y: fc.P.Y}
+		if wide {
+			// Terminals advance the cursor by two columns.
+			cur.X++
+		}
+	}
+	d := t.Sub(c.start)
+	if d < 0 {
+		d = 0
+	}
+	event := []interface{}{d.Seconds(), "o", sb.String()}
+	b, err := json.Marshal(event)
+	if err != nil {
+		c.err = fmt.Errorf("cast: %v", err)
+		return
+	}
+	c.w.Write(b)
+	if err := c.w.WriteByte('\n'); err != nil {
+		c.err = fmt.Errorf("cast: %v", err)
+	}
+}
+
+// sgrColor returns the SGR parameters for a color, with the given base (38
+// for foreground, 48 for background), preceded by a semicolon.
+func sgrColor(c gruid.Color, base int) string {
+	switch {
+	case c == gruid.ColorDefault:
+		return ""
+	case c <= 256:
+		return fmt.Sprintf(";%d;5;%d", base, c-1)
+	default:
+		c--
+		return fmt.Sprintf(";%d;2;%d;%d;%d", base, (c>>16)&0xff, (c>>8)&0xff, c&0xff)
+	}
+}
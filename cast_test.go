@@ -0,0 +1,89 @@
+package sdl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/anaseto/gruid"
+)
+
+// castEvents returns the output of the asciicast events recorded while
+// flushing the given frames, after an initial frame, along with the header.
+func castEvents(t *testing.T, dr *Driver, frames ...gruid.Frame) (map[string]interface{}, []string) {
+	t.Helper()
+	dr.Flush(testFrame(10, 5, "x"))
+	var buf bytes.Buffer
+	if err := dr.StartCast(&buf, "test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, frame := range frames {
+		dr.Flush(frame)
+	}
+	if err := dr.StopCast(); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var header map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, l := range lines[1:] {
+		var ev []interface{}
+		if err := json.Unmarshal([]byte(l), &ev); err != nil {
+			t.Fatal(err)
+		}
+		if len(ev) != 3 || ev[1] != "o" {
+			t.Fatalf("bad event: %v", ev)
+		}
+		out = append(out, ev[2].(string))
+	}
+	return header, out
+}
+
+func TestCastHeader(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	header, out := castEvents(t, dr)
+	if header["version"] != 2.0 || header["width"] != 10.0 || header["height"] != 5.0 || header["title"] != "test" {
+		t.Errorf("bad header: %v", header)
+	}
+	// The current grid is recorded on start.
+	if len(out) != 1 || !strings.HasPrefix(out[0], "\x1b[2J") {
+		t.Errorf("bad initial events: %q", out)
+	}
+}
+
+func TestCastFrame(t *testing.T) {
+	moves := gruid.Frame{Width: 10, Height: 5, Cells: []gruid.FrameCell{
+		{P: gruid.Point{X: 1, Y: 1}, Cell: gruid.Cell{Rune: 'a'}},
+		{P: gruid.Point{X: 4, Y: 1}, Cell: gruid.Cell{Rune: 'b'}},
+	}}
+	styled := testFrame(10, 5, "ab")
+	styled.Cells[1].Cell.Style = gruid.Style{Fg: 2, Bg: 0x123456 + 1}
+	tests := []struct {
+		name  string
+		wide  bool
+		frame gruid.Frame
+		want  string
+	}{
+		{"moves", false, moves, "\x1b[0m\x1b[2;2Ha\x1b[2;5Hb"},
+		{"consecutive", false, testFrame(10, 5, "abc"), "\x1b[0m\x1b[1;1Habc"},
+		{"colors", false, styled, "\x1b[0m\x1b[1;1Ha\x1b[0;38;5;1;48;2;18;52;86mb"},
+		{"wide disabled", false, testFrame(10, 5, "a中xb"), "\x1b[0m\x1b[1;1Ha中xb"},
+		{"wide", true, testFrame(10, 5, "a中xb"), "\x1b[0m\x1b[1;1Ha中b"},
+		{"wide sequence", true, testFrame(10, 5, "中中中b"), "\x1b[0m\x1b[1;1H中中"},
+		{"wide last column", true, testFrame(10, 5, "         中"), "\x1b[0m\x1b[1;1H         中"},
+	}
+	for _, tt := range tests {
+		dr, _ := newTestDriver(t, Config{WideRunes: tt.wide})
+		_, out := castEvents(t, dr, tt.frame)
+		if len(out) != 2 {
+			t.Fatalf("%s: %d events, want 2", tt.name, len(out))
+		}
+		if out[1] != tt.want {
+			t.Errorf("%s: output %q, want %q", tt.name, out[1], tt.want)
+		}
+	}
+}
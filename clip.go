@@ -0,0 +1,69 @@
+package sdl
+
+import (
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// cellBounds returns the rectangle covered by drawing a cell at a given
+// position, including the overlap of oversized tiles.
+func (dr *Driver) cellBounds(c gruid.Cell, x, y int) sdl.Rect {
+	rect := dr.cellRect(x, y)
+	if len(dr.oversized) == 0 {
+		return rect
+	}
+	tr := dr.tileRect(dr.key(c), rect)
+	return rect.Union(&tr)
+}
+
+// dirtyRect returns the bounding rectangle of the regions changed by a frame,
+// taking into account both the previous and new cells. It should be called
+// before updating the grid. It reports false if no region changed.
+func (dr *Driver) dirtyRect(frame gruid.Frame) (sdl.Rect, bool) {
+	w, h := int(dr.width), int(dr.height)
+	var dirty sdl.Rect
+	for _, fc := range frame.Cells {
+		x, y := fc.P.X, fc.P.Y
+		if x < 0 || x >= w || y < 0 || y >= h {
+			continue
+		}
+		old := dr.cellBounds(dr.grid[x+w*y], x, y)
+		rect := dr.cellBounds(fc.Cell, x, y)
+		rect = rect.Union(&old)
+		if dirty.Empty() {
+			dirty = rect
+		} else {
+			dirty = dirty.Union(&rect)
+		}
+	}
+	return dirty, !dirty.Empty()
+}
+
+// canClip reports whether drawing of the whole grid can be restricted to the
+// regions changed by the frame. This is not the case when some content other
+// than the frame's cells might have changed since previous drawing.
+func (dr *Driver) canClip() bool {
+	return !dr.fullRedraw && !dr.overlaid && !dr.hasOverlays() &&
+		len(dr.animations) == 0 && len(dr.blinking) == 0
+}
+
+// setClip restricts drawing to the given rectangle, or disables clipping if
+// nil.
+func (dr *Driver) setClip(rect *sdl.Rect) {
+	err := dr.renderer.SetClipRect(rect)
+	if err != nil {
+		dr.logf("clip rect: %v", err)
+		rect = nil
+	}
+	dr.clip = rect
+}
+
+// clipped reports whether drawing a cell at a given position would have no
+// effect because of clipping.
+func (dr *Driver) clipped(c gruid.Cell, x, y int) bool {
+	if dr.clip == nil {
+		return false
+	}
+	rect := dr.cellBounds(c, x, y)
+	return !rect.HasIntersection(dr.clip)
+}
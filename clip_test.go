@@ -0,0 +1,113 @@
+package sdl
+
+import (
+	"image"
+	"image/draw"
+	"math/rand"
+	"testing"
+
+	"github.com/anaseto/gruid"
+)
+
+// tallTileManager is like testTileManager, but with tiles twice as high for
+// the T rune.
+type tallTileManager struct {
+	testTileManager
+}
+
+func (tm tallTileManager) GetImage(c gruid.Cell) image.Image {
+	img := tm.testTileManager.GetImage(c)
+	if c.Rune != 'T' {
+		return img
+	}
+	tall := image.NewRGBA(image.Rect(0, 0, testTileSize, 2*testTileSize))
+	draw.Draw(tall, tall.Rect, img, image.Point{}, draw.Src)
+	draw.Draw(tall, tall.Rect.Add(image.Point{Y: testTileSize}), img, image.Point{}, draw.Src)
+	return tall
+}
+
+// randomFrames returns frames with a few random changes each, using the
+// given runes.
+func randomFrames(n, w, h int, runes string) []gruid.Frame {
+	rd := rand.New(rand.NewSource(1))
+	rs := []rune(runes)
+	frames := make([]gruid.Frame, n)
+	for i := range frames {
+		frame := gruid.Frame{Width: w, Height: h}
+		changes := 3
+		if i == 0 {
+			changes = w * h
+		}
+		for j := 0; j < changes; j++ {
+			p := gruid.Point{X: rd.Intn(w), Y: rd.Intn(h)}
+			if i == 0 {
+				p = gruid.Point{X: j % w, Y: j / w}
+			}
+			frame.Cells = append(frame.Cells, gruid.FrameCell{P: p, Cell: gruid.Cell{Rune: rs[rd.Intn(len(rs))]}})
+		}
+		frames[i] = frame
+	}
+	return frames
+}
+
+func TestClip(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		runes string
+	}{
+		{"isometric", Config{Layout: IsometricLayout}, "abc."},
+		{"isometric oversized", Config{Layout: IsometricLayout, TileManager: tallTileManager{}}, "abT."},
+		{"oversized", Config{TileManager: tallTileManager{}}, "abT."},
+		{"oversized padding", Config{TileManager: tallTileManager{}, Padding: gruid.Point{X: 3, Y: 5}}, "abT."},
+		{"oversized wide", Config{TileManager: tallTileManager{}, WideRunes: true}, "aT中."},
+	}
+	for _, tt := range tests {
+		dr, _ := newTestDriver(t, tt.cfg)
+		ref, _ := newTestDriver(t, tt.cfg)
+		for i, frame := range randomFrames(30, 10, 5, tt.runes) {
+			dr.Flush(frame)
+			// The reference driver redraws everything each time.
+			ref.fullRedraw = true
+			ref.Flush(frame)
+			if p, ok := sameImages(screenshot(t, dr), screenshot(t, ref)); !ok {
+				t.Errorf("%s: frame %d: rendering differs at %v", tt.name, i, p)
+				break
+			}
+		}
+	}
+}
+
+func TestDirtyRect(t *testing.T) {
+	cell := func(x, y int, r rune) gruid.FrameCell {
+		return gruid.FrameCell{P: gruid.Point{X: x, Y: y}, Cell: gruid.Cell{Rune: r}}
+	}
+	tests := []struct {
+		name   string
+		cells  []gruid.FrameCell
+		x, y   int32
+		w, h   int32
+		change bool
+	}{
+		{"single", []gruid.FrameCell{cell(1, 1, 'x')}, 8, 8, 8, 8, true},
+		{"two", []gruid.FrameCell{cell(0, 2, 'x'), cell(3, 3, 'y')}, 0, 16, 32, 16, true},
+		{"tall", []gruid.FrameCell{cell(1, 2, 'T')}, 8, 8, 8, 16, true},
+		{"replace tall", []gruid.FrameCell{cell(0, 1, 'x')}, 0, 0, 8, 16, true},
+		{"out of grid", []gruid.FrameCell{cell(10, 1, 'x')}, 0, 0, 0, 0, false},
+		{"none", nil, 0, 0, 0, 0, false},
+	}
+	for _, tt := range tests {
+		dr, _ := newTestDriver(t, Config{TileManager: tallTileManager{}})
+		dr.Flush(testFrame(10, 5, "", "T"))
+		rect, ok := dr.dirtyRect(gruid.Frame{Width: 10, Height: 5, Cells: tt.cells})
+		if ok != tt.change {
+			t.Errorf("%s: changed: %v, want %v", tt.name, ok, tt.change)
+		}
+		if !ok {
+			continue
+		}
+		if rect.X != tt.x || rect.Y != tt.y || rect.W != tt.w || rect.H != tt.h {
+			t.Errorf("%s: dirty rect %+v, want (%d,%d) %dx%d", tt.name, rect, tt.x, tt.y, tt.w, tt.h)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package sdl
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+)
+
+// ScreenshotToClipboard places the current window content as a PNG image on
+// the system clipboard, so that players can paste screenshots straight into
+// chat. It uses platform facilities: wl-copy or xclip on Linux and BSDs,
+// osascript on macOS, and PowerShell on Windows. On other platforms, or if
+// the required tool is not available, an error is returned. It should be
+// called from the main routine.
+func (dr *Driver) ScreenshotToClipboard() error {
+	img, err := dr.Screenshot()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return fmt.Errorf("clipboard: %v", err)
+	}
+	err = copyImage(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("clipboard: %v", err)
+	}
+	return nil
+}
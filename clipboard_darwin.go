@@ -0,0 +1,28 @@
+package sdl
+
+import (
+	"os"
+	"os/exec"
+)
+
+// copyImage copies PNG data to the clipboard. The data is read by osascript
+// from a temporary file.
+func copyImage(b []byte) error {
+	f, err := os.CreateTemp("", "gruid-clipboard-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return exec.Command("osascript",
+		"-e", "on run argv",
+		"-e", "set the clipboard to (read (POSIX file (item 1 of argv)) as «class PNGf»)",
+		"-e", "end run",
+		f.Name()).Run()
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !freebsd && !openbsd && !netbsd && !dragonfly && !darwin && !windows
+// +build !linux,!freebsd,!openbsd,!netbsd,!dragonfly,!darwin,!windows
+
+package sdl
+
+func copyImage(b []byte) error {
+	return errUnsupported
+}
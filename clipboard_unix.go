@@ -0,0 +1,23 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+// +build linux freebsd openbsd netbsd dragonfly
+
+package sdl
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+)
+
+// copyImage copies PNG data to the clipboard, using wl-copy under Wayland,
+// and xclip otherwise.
+func copyImage(b []byte) error {
+	var cmd *exec.Cmd
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cmd = exec.Command("wl-copy", "--type", "image/png")
+	} else {
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-t", "image/png", "-i")
+	}
+	cmd.Stdin = bytes.NewReader(b)
+	return cmd.Run()
+}
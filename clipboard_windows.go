@@ -0,0 +1,34 @@
+package sdl
+
+import (
+	"os"
+	"os/exec"
+)
+
+// clipboardScript copies an image file to the clipboard. The file name is
+// passed through the environment to avoid quoting issues.
+const clipboardScript = `Add-Type -AssemblyName System.Windows.Forms
+Add-Type -AssemblyName System.Drawing
+$img = [System.Drawing.Image]::FromFile($env:GRUID_CLIPBOARD_FILE)
+[System.Windows.Forms.Clipboard]::SetImage($img)
+$img.Dispose()`
+
+// copyImage copies PNG data to the clipboard. The data is read by PowerShell
+// from a temporary file.
+func copyImage(b []byte) error {
+	f, err := os.CreateTemp("", "gruid-clipboard-*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-STA", "-Command", clipboardScript)
+	cmd.Env = append(os.Environ(), "GRUID_CLIPBOARD_FILE="+f.Name())
+	return cmd.Run()
+}
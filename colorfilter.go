@@ -0,0 +1,128 @@
+package sdl
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// ColorBlindness represents a kind of color vision deficiency.
+type ColorBlindness int
+
+// Color vision deficiencies handled by color filters.
+const (
+	NormalVision ColorBlindness = iota // no filter
+	Protanopia                         // no red cones
+	Deuteranopia                       // no green cones
+	Tritanopia                         // no blue cones
+)
+
+// ColorFilter describes a color filter applied by the driver to tiles, for
+// accessibility. The filter either simulates how colors are perceived with
+// a given color vision deficiency, which is useful for checking a tileset's
+// readability, or corrects colors so that they are easier to distinguish
+// with that deficiency. Filters are applied to tile images before texture
+// creation, so that the TileManager does not need to regenerate them.
+type ColorFilter struct {
+	Mode    ColorBlindness
+	Correct bool // correct colors instead of simulating the deficiency
+}
+
+// colorMatrices are the simulation matrices for each deficiency, in row
+// order, as given by Machado et al. (2009) for full severity.
+var colorMatrices = [...][9]float32{
+	Protanopia: {
+		0.152286, 1.052583, -0.204868,
+		0.114503, 0.786281, 0.099216,
+		-0.003882, -0.048116, 1.051998},
+	Deuteranopia: {
+		0.367322, 0.860646, -0.227968,
+		0.280085, 0.672501, 0.047413,
+		-0.011820, 0.042940, 0.968881},
+	Tritanopia: {
+		1.255528, -0.076749, -0.178779,
+		-0.078411, 0.930809, 0.148602,
+		0.004733, 0.691367, 0.303900},
+}
+
+// SetColorFilter changes the color filter applied to tiles (see
+// Config.ColorFilter). As it only applies to new textures, the texture cache
+// is cleared, and a redraw requested. If the driver is already running,
+// change will take effect with next Flush so that the function is thread
+// safe.
+func (dr *Driver) SetColorFilter(f ColorFilter) {
+	fn := func() {
+		dr.colorFilter = f
+		if !dr.init {
+			return
+		}
+		dr.ClearCache()
+		select {
+		case dr.reqredraw <- true:
+		default:
+		}
+	}
+	if dr.init {
+		dr.queueSet("SetColorFilter", fn)
+	} else {
+		fn()
+	}
+}
+
+// filtering reports whether a color filter is enabled.
+func (dr *Driver) filtering() bool {
+	m := dr.colorFilter.Mode
+	return m > NormalVision && int(m) < len(colorMatrices)
+}
+
+// filterRGB applies the color filter to 8 bits color components.
+func (dr *Driver) filterRGB(r, g, b uint8) (uint8, uint8, uint8) {
+	if !dr.filtering() {
+		return r, g, b
+	}
+	m := &colorMatrices[dr.colorFilter.Mode]
+	fr, fg, fb := float32(r), float32(g), float32(b)
+	sr := m[0]*fr + m[1]*fg + m[2]*fb
+	sg := m[3]*fr + m[4]*fg + m[5]*fb
+	sb := m[6]*fr + m[7]*fg + m[8]*fb
+	if dr.colorFilter.Correct {
+		// Shift the information lost by the deficiency into
+		// channels that can still be perceived.
+		er, eg, eb := fr-sr, fg-sg, fb-sb
+		sr = fr
+		sg = fg + 0.7*er + eg
+		sb = fb + 0.7*er + eb
+	}
+	return clampUint8(sr), clampUint8(sg), clampUint8(sb)
+}
+
+func clampUint8(x float32) uint8 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 255 {
+		return 255
+	}
+	return uint8(x + 0.5)
+}
+
+// filterColor applies the color filter to an opaque color.
+func (dr *Driver) filterColor(c color.RGBA) color.RGBA {
+	c.R, c.G, c.B = dr.filterRGB(c.R, c.G, c.B)
+	return c
+}
+
+// filterImage returns a copy of a tile image with the color filter applied,
+// or the image itself if no filter is enabled.
+func (dr *Driver) filterImage(img image.Image) image.Image {
+	if !dr.filtering() {
+		return img
+	}
+	b := img.Bounds()
+	m := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(m, m.Rect, img, b.Min, draw.Src)
+	for i := 0; i+3 < len(m.Pix); i += 4 {
+		m.Pix[i], m.Pix[i+1], m.Pix[i+2] = dr.filterRGB(m.Pix[i], m.Pix[i+1], m.Pix[i+2])
+	}
+	return m
+}
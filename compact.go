@@ -0,0 +1,49 @@
+package sdl
+
+import (
+	"image"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// setCompactTextures makes the renderer create 16 bits textures, if
+// supported: RGB565 for opaque tiles, and ARGB4444 for tiles with
+// transparency.
+func (dr *Driver) setCompactTextures() {
+	info, err := dr.renderer.GetInfo()
+	if err != nil {
+		dr.logf("compact textures: %v", err)
+		return
+	}
+	supported := func(format uint32) uint32 {
+		for i := uint32(0); i < info.NumTextureFormats && i < uint32(len(info.TextureFormats)); i++ {
+			if uint32(info.TextureFormats[i]) == format {
+				return format
+			}
+		}
+		return 0
+	}
+	opaque := supported(sdl.PIXELFORMAT_RGB565)
+	alpha := supported(sdl.PIXELFORMAT_ARGB4444)
+	if opaque == 0 && alpha == 0 {
+		dr.logf("compact textures: no 16 bits texture formats supported by renderer %s", info.Name)
+		return
+	}
+	dr.renderer.setTextureFormats(opaque, alpha)
+}
+
+// isOpaque reports whether an image is fully opaque.
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package sdl
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/anaseto/gruid"
+)
+
+// layers is a TileManager drawing tiles from several TileManagers on top of
+// each other.
+type layers struct {
+	base   TileManager
+	layers []TileManager
+}
+
+// Layers returns a TileManager that draws tiles from the base TileManager
+// and then, on top of them, tiles from the other TileManagers, in order.
+// Layer TileManagers may return nil for cells they do not decorate, and
+// should normally return images with transparent backgrounds. This allows,
+// for example, to add status icons in cell corners without rewriting the
+// base TileManager. The tile size is the base's one, and layer tiles are
+// drawn at the tile's top-left corner.
+//
+// The returned TileManager implements TileManagerErr, reporting errors from
+// any of the TileManagers.
+func Layers(base TileManager, tms ...TileManager) TileManager {
+	return &layers{base: base, layers: tms}
+}
+
+func (l *layers) GetImage(c gruid.Cell) image.Image {
+	img, _ := l.GetImageErr(c)
+	return img
+}
+
+func (l *layers) GetImageErr(c gruid.Cell) (image.Image, error) {
+	img, err := getTile(l.base, c)
+	if err != nil || img == nil {
+		return nil, err
+	}
+	var rgba *image.RGBA
+	for _, tm := range l.layers {
+		limg, err := getTile(tm, c)
+		if err != nil {
+			return nil, err
+		}
+		if limg == nil {
+			continue
+		}
+		if rgba == nil {
+			// Copy the base image, as the TileManager may reuse
+			// it.
+			rgba = image.NewRGBA(img.Bounds().Sub(img.Bounds().Min))
+			draw.Draw(rgba, rgba.Rect, img, img.Bounds().Min, draw.Src)
+		}
+		draw.Draw(rgba, rgba.Rect, limg, limg.Bounds().Min, draw.Over)
+	}
+	if rgba == nil {
+		return img, nil
+	}
+	return rgba, nil
+}
+
+func (l *layers) TileSize() gruid.Point {
+	return l.base.TileSize()
+}
+
+// selector is a TileManager choosing between two TileManagers for each
+// cell.
+type selector struct {
+	pred      func(gruid.Cell) bool
+	tm        TileManager
+	otherwise TileManager
+}
+
+// Select returns a TileManager that uses tm for cells satisfying the given
+// predicate, and otherwise for the other cells. Both TileManagers should
+// have the same tile size: the returned TileManager reports the tile size of
+// otherwise.
+//
+// The returned TileManager implements TileManagerErr, reporting errors from
+// either TileManager.
+func Select(pred func(gruid.Cell) bool, tm, otherwise TileManager) TileManager {
+	return &selector{pred: pred, tm: tm, otherwise: otherwise}
+}
+
+func (s *selector) GetImage(c gruid.Cell) image.Image {
+	img, _ := s.GetImageErr(c)
+	return img
+}
+
+func (s *selector) GetImageErr(c gruid.Cell) (image.Image, error) {
+	if s.pred(c) {
+		return getTile(s.tm, c)
+	}
+	return getTile(s.otherwise, c)
+}
+
+func (s *selector) TileSize() gruid.Point {
+	return s.otherwise.TileSize()
+}
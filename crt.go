@@ -0,0 +1,114 @@
+package sdl
+
+import (
+	"image"
+	"math"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// CRTFilter describes a retro post-processing filter imitating old CRT
+// monitors. It is drawn as a translucent overlay texture on top of the grid,
+// so that it works with the software renderer too.
+type CRTFilter struct {
+	// Scanlines is the darkening of every other pixel row, between 0
+	// (none) and 1 (black lines).
+	Scanlines float32
+
+	// Vignette is the darkening towards the window corners, between 0
+	// (none) and 1 (black corners).
+	Vignette float32
+
+	// Curvature approximates a curved screen by masking the window
+	// corners with rounded black borders.
+	Curvature bool
+}
+
+// crtOverlay is the cached overlay texture for a CRT filter.
+type crtOverlay struct {
+	tx     texture
+	filter CRTFilter
+	w, h   int32
+}
+
+// SetCRTFilter enables a CRT filter drawn on top of the grid on each Flush,
+// or disables it if nil. If the driver is already running, change will take
+// effect with next Flush so that the function is thread safe.
+func (dr *Driver) SetCRTFilter(f *CRTFilter) {
+	var crt *CRTFilter
+	if f != nil {
+		cp := *f
+		crt = &cp
+	}
+	fn := func() {
+		dr.crt = crt
+	}
+	if dr.init {
+		dr.queueSet("SetCRTFilter", fn)
+	} else {
+		fn()
+	}
+}
+
+// drawCRT draws the CRT filter overlay, generating it first if the filter or
+// window size changed.
+func (dr *Driver) drawCRT() {
+	w, h := dr.windowSize()
+	ov := &dr.crtOverlay
+	if ov.tx == nil || ov.filter != *dr.crt || ov.w != w || ov.h != h {
+		dr.clearCRT()
+		tx, err := dr.renderer.createTexture(crtImage(*dr.crt, int(w), int(h)))
+		if err != nil {
+			dr.logf("crt filter: %v", err)
+			return
+		}
+		tx.SetBlendMode(sdl.BLENDMODE_BLEND)
+		*ov = crtOverlay{tx: tx, filter: *dr.crt, w: w, h: h}
+	}
+	err := dr.renderer.copy(ov.tx, nil, &sdl.Rect{W: w, H: h})
+	if err != nil {
+		dr.logf("crt filter: %v", err)
+	}
+}
+
+// clearCRT releases the CRT filter overlay texture, if any.
+func (dr *Driver) clearCRT() {
+	if dr.crtOverlay.tx != nil {
+		err := dr.crtOverlay.tx.Destroy()
+		if err != nil {
+			dr.logf("crt filter destroy: %v", err)
+		}
+	}
+	dr.crtOverlay = crtOverlay{}
+}
+
+// crtImage returns a black image whose alpha channel implements the filter.
+func crtImage(f CRTFilter, w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		ny := 2*(float64(y)+0.5)/float64(h) - 1
+		var scan float64
+		if y%2 == 1 {
+			scan = float64(f.Scanlines)
+		}
+		for x := 0; x < w; x++ {
+			nx := 2*(float64(x)+0.5)/float64(w) - 1
+			a := scan
+			if f.Vignette > 0 {
+				d := (nx*nx + ny*ny) / 2
+				v := float64(f.Vignette) * d * d
+				a = 1 - (1-a)*(1-v)
+			}
+			if f.Curvature && math.Pow(math.Abs(nx), 8)+math.Pow(math.Abs(ny), 8) > 1 {
+				a = 1
+			}
+			if a > 1 {
+				a = 1
+			} else if a < 0 {
+				a = 0
+			}
+			img.Pix[y*img.Stride+x*4+3] = uint8(a * 255)
+		}
+	}
+	return img
+}
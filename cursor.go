@@ -0,0 +1,84 @@
+package sdl
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// updateCursor shows or hides the mouse cursor depending on the event, when
+// cursor auto-hiding is enabled (see Config.HideCursor).
+func (dr *Driver) updateCursor(event sdl.Event) {
+	if !dr.hideCursor && dr.cursorIdle <= 0 {
+		return
+	}
+	switch ev := event.(type) {
+	case *sdl.KeyboardEvent:
+		if dr.hideCursor && ev.Type == sdl.KEYDOWN {
+			dr.showCursor(false)
+		}
+	case *sdl.MouseMotionEvent, *sdl.MouseButtonEvent, *sdl.MouseWheelEvent:
+		dr.lastMotion = time.Now()
+		dr.showCursor(true)
+	}
+}
+
+// checkCursorIdle hides the mouse cursor if the mouse has been idle for
+// longer than the configured timeout.
+func (dr *Driver) checkCursorIdle() {
+	if dr.cursorIdle <= 0 || dr.cursorHidden {
+		return
+	}
+	if dr.lastMotion.IsZero() {
+		dr.lastMotion = time.Now()
+		return
+	}
+	if time.Since(dr.lastMotion) >= dr.cursorIdle {
+		dr.showCursor(false)
+	}
+}
+
+// showCursor shows or hides the mouse cursor.
+func (dr *Driver) showCursor(show bool) {
+	if dr.cursorHidden == !show {
+		return
+	}
+	err := dr.backend.showCursor(show)
+	if err != nil {
+		dr.logf("show cursor: %v", err)
+		return
+	}
+	dr.cursorHidden = !show
+}
+
+// updateMouseIdle records mouse activity, and queues a MsgMouseIdle if the
+// mouse was idle (see Config.MouseIdleTimeout).
+func (dr *Driver) updateMouseIdle(event sdl.Event) {
+	if dr.mouseIdle <= 0 {
+		return
+	}
+	switch event.(type) {
+	case *sdl.MouseMotionEvent, *sdl.MouseButtonEvent, *sdl.MouseWheelEvent:
+		dr.lastMouse = time.Now()
+		if dr.mouseIdleSent {
+			dr.mouseIdleSent = false
+			dr.msgs = append(dr.msgs, MsgMouseIdle{Idle: false, P: dr.mousepos, Time: dr.lastMouse})
+		}
+	}
+}
+
+// checkMouseIdle queues a MsgMouseIdle if the mouse has been idle for longer
+// than the configured duration.
+func (dr *Driver) checkMouseIdle() {
+	if dr.mouseIdle <= 0 || dr.mouseIdleSent {
+		return
+	}
+	if dr.lastMouse.IsZero() {
+		dr.lastMouse = time.Now()
+		return
+	}
+	if time.Since(dr.lastMouse) >= dr.mouseIdle {
+		dr.mouseIdleSent = true
+		dr.msgs = append(dr.msgs, MsgMouseIdle{Idle: true, P: dr.mousepos, Time: time.Now()})
+	}
+}
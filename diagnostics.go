@@ -0,0 +1,208 @@
+package sdl
+
+import (
+	"archive/zip"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// historySize is the number of entries kept in a history.
+const historySize = 64
+
+// history keeps track of recent timestamped entries, such as log lines or
+// input messages, for diagnostic purposes.
+type history struct {
+	mu      sync.Mutex
+	entries []historyEntry
+	next    int
+}
+
+// historyEntry is a history entry. Its value is either a string or an input
+// message, formatted only when needed, so that recording input messages does
+// not allocate.
+type historyEntry struct {
+	t time.Time
+	v interface{}
+}
+
+func (e historyEntry) String() string {
+	s, ok := e.v.(string)
+	if !ok {
+		s = fmt.Sprintf("%T %+v", e.v, e.v)
+	}
+	return e.t.Format("15:04:05.000") + " " + s
+}
+
+func (h *history) add(t time.Time, v interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e := historyEntry{t: t, v: v}
+	if len(h.entries) < historySize {
+		h.entries = append(h.entries, e)
+		return
+	}
+	h.entries[h.next] = e
+	h.next = (h.next + 1) % historySize
+}
+
+// lines returns the entries from oldest to newest.
+func (h *history) lines() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	lines := make([]string, 0, len(h.entries))
+	for _, e := range h.entries[h.next:] {
+		lines = append(lines, e.String())
+	}
+	for _, e := range h.entries[:h.next] {
+		lines = append(lines, e.String())
+	}
+	return lines
+}
+
+// String returns the entries from oldest to newest, one per line.
+func (h *history) String() string {
+	var sb strings.Builder
+	for _, l := range h.lines() {
+		sb.WriteString(l)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// WriteDiagnostics writes a diagnostic bundle as a zip archive, suitable for
+// bug reports. The bundle contains an image of the last frame, recent input
+// messages and logs, the driver's configuration, and SDL and renderer
+// information. It should be called from the main routine, but it can be used
+// after Close.
+func (dr *Driver) WriteDiagnostics(w io.Writer) error {
+	return dr.writeDiagnostics(w, "")
+}
+
+// SaveDiagnostics writes a diagnostic bundle (see WriteDiagnostics) into a
+// timestamped zip file in the given directory, which is created if necessary.
+// It returns the path of the written file.
+func (dr *Driver) SaveDiagnostics(dir string) (string, error) {
+	return dr.saveDiagnostics(dir, "")
+}
+
+// HandlePanic is meant to be deferred in the main function. When recovering
+// from a panic, it writes a diagnostic bundle, including the panic's value
+// and stack trace, into the given directory, and then panics again with the
+// same value. Note that gruid.App catches panics by default, so you may want
+// to set its CatchPanics field to false.
+func (dr *Driver) HandlePanic(dir string) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	info := fmt.Sprintf("panic: %v\n\n%s", r, debug.Stack())
+	path, err := dr.saveDiagnostics(dir, info)
+	if err != nil {
+		dr.logf("diagnostics: %v", err)
+	} else {
+		dr.logf("diagnostics written to %s", path)
+	}
+	panic(r)
+}
+
+func (dr *Driver) saveDiagnostics(dir, panicInfo string) (string, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	name := "diagnostics-" + time.Now().Format("20060102-150405.000") + ".zip"
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	err = dr.writeDiagnostics(f, panicInfo)
+	if err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+func (dr *Driver) writeDiagnostics(w io.Writer, panicInfo string) error {
+	zw := zip.NewWriter(w)
+	add := func(name, content string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, content)
+		return err
+	}
+	if panicInfo != "" {
+		if err := add("panic.txt", panicInfo); err != nil {
+			return err
+		}
+	}
+	f, err := zw.Create("frame.png")
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, dr.composeGrid()); err != nil {
+		return err
+	}
+	if err := add("inputs.txt", dr.inputs.String()); err != nil {
+		return err
+	}
+	if err := add("logs.txt", dr.logs.String()); err != nil {
+		return err
+	}
+	if err := add("config.txt", dr.configInfo()); err != nil {
+		return err
+	}
+	if err := add("system.txt", dr.systemInfo()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// configInfo returns a description of the driver's configuration.
+func (dr *Driver) configInfo() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "size: %dx%d cells\n", dr.width, dr.height)
+	fmt.Fprintf(&sb, "tile size: %dx%d\n", dr.tw, dr.th)
+	fmt.Fprintf(&sb, "tile manager: %T\n", dr.tm)
+	fmt.Fprintf(&sb, "scale: %gx%g\n", dr.scaleX, dr.scaleY)
+	fmt.Fprintf(&sb, "fullscreen: %v\n", dr.fullscreen)
+	fmt.Fprintf(&sb, "accelerated: %v\n", dr.accelerated)
+	fmt.Fprintf(&sb, "vsync: %v\n", dr.vsync)
+	fmt.Fprintf(&sb, "backend: %T\n", dr.backend)
+	fmt.Fprintf(&sb, "cached textures: %d\n", len(dr.textures))
+	fmt.Fprintf(&sb, "stats: %+v\n", dr.Stats())
+	return sb.String()
+}
+
+// systemInfo returns a description of the system, SDL and renderer.
+func (dr *Driver) systemInfo() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "os/arch: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&sb, "go: %s\n", runtime.Version())
+	var v sdl.Version
+	sdl.GetVersion(&v)
+	fmt.Fprintf(&sb, "sdl: %d.%d.%d (%s)\n", v.Major, v.Minor, v.Patch, sdl.GetRevision())
+	if dr.init {
+		info, err := dr.renderer.GetInfo()
+		if err != nil {
+			fmt.Fprintf(&sb, "renderer: %v\n", err)
+		} else {
+			fmt.Fprintf(&sb, "renderer: %s (flags: %#x, max texture: %dx%d)\n",
+				info.Name, info.Flags, info.MaxTextureWidth, info.MaxTextureHeight)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,124 @@
+package sdl
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"image/png"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestHistory(t *testing.T) {
+	var h history
+	t0 := time.Date(2020, 1, 1, 10, 20, 30, 0, time.UTC)
+	for i := 0; i < historySize+3; i++ {
+		h.add(t0.Add(time.Duration(i)*time.Millisecond), fmt.Sprint(i))
+	}
+	lines := h.lines()
+	if len(lines) != historySize {
+		t.Fatalf("%d lines, want %d", len(lines), historySize)
+	}
+	if want := "10:20:30.003 3"; lines[0] != want {
+		t.Errorf("oldest line %q, want %q", lines[0], want)
+	}
+	if want := fmt.Sprintf("10:20:30.%03d %d", historySize+2, historySize+2); lines[historySize-1] != want {
+		t.Errorf("newest line %q, want %q", lines[historySize-1], want)
+	}
+	h = history{}
+	h.add(t0, gruid.MsgKeyDown{Key: gruid.KeyEnter})
+	if got, want := h.String(), "10:20:30.000 gruid.MsgKeyDown {Key:Enter"; !strings.HasPrefix(got, want) {
+		t.Errorf("message entry %q, want prefix %q", got, want)
+	}
+}
+
+// readZip returns the files of a zip archive.
+func readZip(t *testing.T, b []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], err = ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	dr, hl := newTestDriver(t, Config{})
+	dr.Flush(testFrame(10, 5, "ab"))
+	pollAll(t, dr, hl, 0, keyDown(sdl.K_ESCAPE, 0))
+	dr.logf("some log")
+	var buf bytes.Buffer
+	if err := dr.WriteDiagnostics(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if _, ok := files["panic.txt"]; ok {
+		t.Error("unexpected panic.txt")
+	}
+	img, err := png.Decode(bytes.NewReader(files["frame.png"]))
+	if err != nil {
+		t.Fatalf("frame.png: %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != 80 || size.Y != 40 {
+		t.Errorf("frame size %v, want 80x40", size)
+	}
+	contains := []struct {
+		file, s string
+	}{
+		{"inputs.txt", "MsgKeyDown {Key:Escape"},
+		{"logs.txt", "some log"},
+		{"config.txt", "size: 10x5 cells"},
+		{"config.txt", "backend: *sdl.headless"},
+		{"system.txt", "renderer: "},
+	}
+	for _, c := range contains {
+		if !strings.Contains(string(files[c.file]), c.s) {
+			t.Errorf("%s does not contain %q:\n%s", c.file, c.s, files[c.file])
+		}
+	}
+}
+
+func TestHandlePanic(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{Logger: log.New(ioutil.Discard, "", 0)})
+	dir := filepath.Join(t.TempDir(), "diag")
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recovered %v, want boom", r)
+			}
+		}()
+		defer dr.HandlePanic(dir)
+		panic("boom")
+	}()
+	paths, err := filepath.Glob(filepath.Join(dir, "diagnostics-*.zip"))
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("diagnostics files: %v (%v)", paths, err)
+	}
+	b, err := ioutil.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, b)
+	if s := string(files["panic.txt"]); !strings.HasPrefix(s, "panic: boom") || !strings.Contains(s, "TestHandlePanic") {
+		t.Errorf("bad panic.txt:\n%s", s)
+	}
+}
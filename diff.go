@@ -0,0 +1,26 @@
+package sdl
+
+import (
+	"github.com/anaseto/gruid"
+)
+
+// diffFrame returns a frame with only the cells that differ from the last
+// drawn ones, so that unchanged cells are not drawn again. If the window
+// content is not known to match the grid, the frame is returned unchanged.
+// The returned frame's cells are only valid until next call.
+func (dr *Driver) diffFrame(frame gruid.Frame) gruid.Frame {
+	w, h := int(dr.width), int(dr.height)
+	if !dr.drawn || len(dr.grid) != w*h {
+		return frame
+	}
+	cells := dr.diffCells[:0]
+	for _, fc := range frame.Cells {
+		if fc.P.X >= 0 && fc.P.X < w && fc.P.Y >= 0 && fc.P.Y < h && dr.grid[fc.P.X+w*fc.P.Y] == fc.Cell {
+			continue
+		}
+		cells = append(cells, fc)
+	}
+	dr.diffCells = cells
+	frame.Cells = cells
+	return frame
+}
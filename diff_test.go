@@ -0,0 +1,58 @@
+package sdl
+
+import (
+	"testing"
+
+	"github.com/anaseto/gruid"
+)
+
+func TestDiffFrame(t *testing.T) {
+	cell := func(x, y int, r rune) gruid.FrameCell {
+		return gruid.FrameCell{P: gruid.Point{X: x, Y: y}, Cell: gruid.Cell{Rune: r}}
+	}
+	styled := cell(1, 0, 'b')
+	styled.Cell.Style.Fg = 3
+	tests := []struct {
+		name  string
+		cells []gruid.FrameCell
+		want  []gruid.FrameCell
+	}{
+		{"unchanged", []gruid.FrameCell{cell(0, 0, 'a'), cell(1, 0, 'b')}, nil},
+		{"changed rune", []gruid.FrameCell{cell(0, 0, 'a'), cell(1, 0, 'c')}, []gruid.FrameCell{cell(1, 0, 'c')}},
+		{"changed style", []gruid.FrameCell{styled}, []gruid.FrameCell{styled}},
+		{"new cell", []gruid.FrameCell{cell(3, 4, 'd')}, []gruid.FrameCell{cell(3, 4, 'd')}},
+		{"out of grid", []gruid.FrameCell{cell(10, 0, 'a'), cell(0, -1, 'a')}, []gruid.FrameCell{cell(10, 0, 'a'), cell(0, -1, 'a')}},
+		{"empty", nil, nil},
+	}
+	dr, _ := newTestDriver(t, Config{})
+	first := testFrame(10, 5, "ab")
+	if diff := dr.diffFrame(first); len(diff.Cells) != len(first.Cells) {
+		t.Errorf("before drawing: got %d cells, want %d", len(diff.Cells), len(first.Cells))
+	}
+	dr.Flush(first)
+	for _, tt := range tests {
+		diff := dr.diffFrame(gruid.Frame{Width: 10, Height: 5, Cells: tt.cells})
+		if len(diff.Cells) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, diff.Cells, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if diff.Cells[i] != tt.want[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, diff.Cells, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFlushSkipsUnchanged(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	frame := testFrame(10, 5, "ab")
+	dr.Flush(frame)
+	dr.Flush(frame)
+	dr.Flush(testFrame(10, 5, "ac"))
+	st := dr.Stats()
+	if st.Frames != 2 || st.Skipped != 1 {
+		t.Errorf("got %d frames and %d skipped, want 2 and 1", st.Frames, st.Skipped)
+	}
+}
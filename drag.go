@@ -0,0 +1,110 @@
+package sdl
+
+import (
+	"image"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// DragGhost describes a translucent image following the mouse cursor at
+// pixel precision during a drag, for drag-and-drop user interfaces.
+type DragGhost struct {
+	// Image is the ghost's image. If nil, the tile for Cell is used.
+	Image image.Image
+
+	// Cell is the cell whose tile is used when Image is nil.
+	Cell gruid.Cell
+
+	// Hot is the position in the image that follows the mouse pointer.
+	Hot image.Point
+
+	// Alpha is the ghost's opacity (default: 160).
+	Alpha uint8
+}
+
+// SetDragGhost shows a ghost image following the mouse cursor, or removes it
+// if nil. The ghost is removed automatically when a mouse button is
+// released, so it is typically set when handling the gruid.MouseMain message
+// starting a drag. It should be called from the main routine, for example
+// from the Update method of a gruid.Model.
+func (dr *Driver) SetDragGhost(g *DragGhost) {
+	dr.clearDragGhost()
+	if g == nil || !dr.init {
+		return
+	}
+	img := g.Image
+	if img == nil {
+		var err error
+		img, err = dr.getImage(g.Cell)
+		if err != nil {
+			dr.handleError(err)
+			return
+		}
+	}
+	tx, err := dr.renderer.createTexture(img)
+	if err == nil {
+		alpha := g.Alpha
+		if alpha == 0 {
+			alpha = 160
+		}
+		err = tx.SetBlendMode(sdl.BLENDMODE_BLEND)
+		if err == nil {
+			err = tx.SetAlphaMod(alpha)
+		}
+		if err != nil {
+			tx.Destroy()
+		}
+	}
+	if err != nil {
+		dr.logf("drag ghost: %v", err)
+		return
+	}
+	dr.dragGhost = &dragGhost{tx: tx, size: img.Bounds().Size(), hot: g.Hot}
+	dr.needRefresh = true
+}
+
+// dragGhost is the texture of the current drag ghost.
+type dragGhost struct {
+	tx   texture
+	size image.Point
+	hot  image.Point
+}
+
+// clearDragGhost removes the current drag ghost, if any.
+func (dr *Driver) clearDragGhost() {
+	if dr.dragGhost == nil {
+		return
+	}
+	dr.dragGhost.tx.Destroy()
+	dr.dragGhost = nil
+	dr.needRefresh = true
+}
+
+// trackMouse records the mouse position in window pixels, and updates the
+// drag ghost.
+func (dr *Driver) trackMouse(event sdl.Event) {
+	switch ev := event.(type) {
+	case *sdl.MouseMotionEvent:
+		dr.mousePixel = image.Point{X: int(ev.X), Y: int(ev.Y)}
+		if dr.dragGhost != nil {
+			dr.needRefresh = true
+		}
+	case *sdl.MouseButtonEvent:
+		dr.mousePixel = image.Point{X: int(ev.X), Y: int(ev.Y)}
+		if ev.Type == sdl.MOUSEBUTTONUP {
+			dr.clearDragGhost()
+		}
+	}
+}
+
+// drawDragGhost draws the drag ghost at the mouse position.
+func (dr *Driver) drawDragGhost() {
+	g := dr.dragGhost
+	p := dr.unscale(int32(dr.mousePixel.X), int32(dr.mousePixel.Y)).Sub(g.hot)
+	rect := sdl.Rect{X: int32(p.X), Y: int32(p.Y), W: int32(g.size.X), H: int32(g.size.Y)}
+	err := dr.renderer.copy(g.tx, nil, &rect)
+	if err != nil {
+		dr.logf("drag ghost: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package sdl
+
+import (
+	"time"
+)
+
+// MsgDriverError is reported when rendering failures accumulate, as
+// configured by the ErrorInterval and ErrorThreshold options. It allows the
+// application to warn the user, or to switch to another renderer, for
+// example by starting again with the Accelerated option disabled.
+type MsgDriverError struct {
+	Textures int           // number of failed texture creations
+	Copies   int           // number of failed texture copies
+	Err      error         // last SDL error message, if any
+	Interval time.Duration // duration covered by the failure counters
+	Time     time.Time     // time when the message was generated
+}
+
+// failures accumulates rendering failures between two checks.
+type failures struct {
+	start    time.Time
+	textures int
+	copies   int
+}
+
+// addTextureFailure records a failed texture creation.
+func (dr *Driver) addTextureFailure() {
+	dr.failures.textures++
+}
+
+// addCopyFailure records a failed texture copy.
+func (dr *Driver) addCopyFailure() {
+	dr.failures.copies++
+}
+
+// checkErrors reports a MsgDriverError message if enough failures happened
+// since last check, when the error check interval elapsed.
+func (dr *Driver) checkErrors() {
+	if dr.errorInterval <= 0 {
+		return
+	}
+	now := time.Now()
+	fl := &dr.failures
+	if fl.start.IsZero() {
+		fl.start = now
+	}
+	if now.Sub(fl.start) < dr.errorInterval {
+		return
+	}
+	err := dr.backend.lastError()
+	n := fl.textures + fl.copies
+	if n > 0 && n >= dr.errorThreshold {
+		msg := MsgDriverError{
+			Textures: fl.textures,
+			Copies:   fl.copies,
+			Err:      err,
+			Interval: now.Sub(fl.start),
+			Time:     now,
+		}
+		dr.logf("driver errors: %d texture and %d copy failures (last: %v)", msg.Textures, msg.Copies, err)
+		dr.msgs = append(dr.msgs, msg)
+	}
+	*fl = failures{start: now}
+}
@@ -0,0 +1,131 @@
+package sdl
+
+import (
+	"image"
+	"image/draw"
+	"io/fs"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// italicSlant is the horizontal shift per pixel of height used for
+// synthesized italics (about 11 degrees).
+const italicSlant = 0.2
+
+// FaceOptions describes font rendering quality options. Different options
+// may be used for different cell styles, by using one face per style in a
+// TileManager, for example bold synthesis for cells with a bold attribute.
+//
+// Subpixel (LCD) rendering is not provided: font.Face glyphs are alpha
+// masks, so per-channel coverage cannot be represented.
+type FaceOptions struct {
+	// Hinting is the hinting mode used for glyph outlines. It is only
+	// used by LoadFaceOptions.
+	Hinting font.Hinting
+
+	// Aliased disables antialiasing, producing crisp bitmap-like glyphs.
+	Aliased bool
+
+	// Bold makes glyphs bolder, by thickening them horizontally by one
+	// pixel, for fonts without a bold variant.
+	Bold bool
+
+	// Italic slants glyphs, for fonts without an italic variant.
+	Italic bool
+}
+
+// LoadFaceOptions is like LoadFace, but uses the given rendering options.
+// LoadFace is equivalent to using full hinting and no other options.
+func LoadFaceOptions(fsys fs.FS, name string, size, dpi float64, opts FaceOptions) (font.Face, error) {
+	face, err := loadFace(fsys, name, size, dpi, opts.Hinting)
+	if err != nil {
+		return nil, err
+	}
+	return NewStyledFace(face, opts), nil
+}
+
+// NewStyledFace returns a face rendering glyphs of the given face with the
+// given antialiasing, bold and italic options. The Hinting option is ignored,
+// as it has to be specified when creating the original face. Metrics and
+// advances are those of the original face, so that tiles produced by gruid's
+// tiles.Drawer keep the same size.
+func NewStyledFace(face font.Face, opts FaceOptions) font.Face {
+	if !opts.Aliased && !opts.Bold && !opts.Italic {
+		return face
+	}
+	return &styledFace{Face: face, opts: opts}
+}
+
+// styledFace is a font.Face applying FaceOptions to another face's glyphs.
+type styledFace struct {
+	font.Face
+	opts FaceOptions
+}
+
+// shift returns the italic horizontal shift for a pixel row, given the
+// baseline's row.
+func (sf *styledFace) shift(y, baseline int) int {
+	if !sf.opts.Italic {
+		return 0
+	}
+	return int(math.Round(float64(baseline-y) * italicSlant))
+}
+
+func (sf *styledFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	dr, mask, mp, adv, ok := sf.Face.Glyph(dot, r)
+	if !ok || dr.Empty() || mask == nil {
+		return dr, mask, mp, adv, ok
+	}
+	src := image.NewAlpha(dr)
+	draw.Draw(src, dr, mask, mp, draw.Src)
+	baseline := dot.Y.Round()
+	ndr := dr
+	ndr.Min.X += sf.shift(dr.Max.Y-1, baseline)
+	ndr.Max.X += sf.shift(dr.Min.Y, baseline)
+	if sf.opts.Bold {
+		ndr.Max.X++
+	}
+	dst := image.NewAlpha(ndr)
+	for y := dr.Min.Y; y < dr.Max.Y; y++ {
+		sh := sf.shift(y, baseline)
+		for x := dr.Min.X; x < dr.Max.X; x++ {
+			a := src.AlphaAt(x, y).A
+			if a == 0 {
+				continue
+			}
+			if sf.opts.Aliased {
+				if a < 128 {
+					continue
+				}
+				a = 255
+			}
+			i := dst.PixOffset(x+sh, y)
+			if a > dst.Pix[i] {
+				dst.Pix[i] = a
+			}
+			if sf.opts.Bold && a > dst.Pix[i+1] {
+				dst.Pix[i+1] = a
+			}
+		}
+	}
+	return ndr, dst, ndr.Min, adv, true
+}
+
+func (sf *styledFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	bounds, adv, ok := sf.Face.GlyphBounds(r)
+	if !ok {
+		return bounds, adv, ok
+	}
+	if sf.opts.Italic {
+		// Coordinates are relative to the dot, with y growing
+		// downwards.
+		bounds.Min.X -= fixed.Int26_6(float64(bounds.Max.Y) * italicSlant)
+		bounds.Max.X -= fixed.Int26_6(float64(bounds.Min.Y) * italicSlant)
+	}
+	if sf.opts.Bold {
+		bounds.Max.X += fixed.I(1)
+	}
+	return bounds, adv, ok
+}
@@ -0,0 +1,138 @@
+package sdl
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// notdefRune is a noncharacter used for finding a face's replacement glyph.
+const notdefRune = '\uffff'
+
+// fallbackFace is a font.Face using glyphs from a list of faces.
+type fallbackFace struct {
+	faces []font.Face
+	mu    sync.Mutex
+	index map[rune]int // index of the face used for a rune
+}
+
+// NewFallbackFace returns a font.Face that renders each rune with the first
+// of the given faces that provides a glyph for it, so that runes missing from
+// the primary font, such as box drawing characters, arrows or rare symbols,
+// are rendered from a fallback font instead of as blanks or tofu. At least
+// one face should be provided. The metrics are those of the first face. The
+// returned face can be used with gruid's tiles.Drawer for building a
+// TileManager.
+//
+// A face is considered to lack a glyph for a rune when its Glyph method
+// reports so, or when it returns its replacement glyph, as fonts produced by
+// the opentype package do. For best results, fallback faces should have the
+// same size as the primary one.
+func NewFallbackFace(faces ...font.Face) font.Face {
+	return &fallbackFace{faces: faces, index: make(map[rune]int)}
+}
+
+// face returns the face to be used for a rune.
+func (ff *fallbackFace) face(r rune) font.Face {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	i, ok := ff.index[r]
+	if !ok {
+		i = ff.lookup(r)
+		ff.index[r] = i
+	}
+	return ff.faces[i]
+}
+
+// lookup returns the index of the first face having a glyph for a rune, or
+// zero if there is none.
+func (ff *fallbackFace) lookup(r rune) int {
+	for i, f := range ff.faces {
+		if hasGlyph(f, r) {
+			return i
+		}
+	}
+	return 0
+}
+
+// hasGlyph reports whether a face provides a glyph for a rune, other than
+// its replacement glyph.
+func hasGlyph(f font.Face, r rune) bool {
+	dr, mask, mp, adv, ok := f.Glyph(fixed.Point26_6{}, r)
+	if !ok {
+		return false
+	}
+	// The mask may be reused by the next call to Glyph, so we have to copy
+	// it.
+	mask, mp = copyMask(mask, mp, dr.Size()), image.Point{}
+	ndr, nmask, nmp, nadv, nok := f.Glyph(fixed.Point26_6{}, notdefRune)
+	if !nok || dr != ndr || adv != nadv {
+		return true
+	}
+	return !sameMask(mask, mp, nmask, nmp, dr.Size())
+}
+
+// copyMask returns a copy of the area of the given size of a glyph mask.
+func copyMask(m image.Image, p image.Point, size image.Point) image.Image {
+	if m == nil {
+		return nil
+	}
+	cp := image.NewAlpha(image.Rect(0, 0, size.X, size.Y))
+	draw.Draw(cp, cp.Rect, m, p, draw.Src)
+	return cp
+}
+
+// sameMask reports whether two glyph masks are identical over an area of the
+// given size.
+func sameMask(m1 image.Image, p1 image.Point, m2 image.Image, p2 image.Point, size image.Point) bool {
+	if m1 == nil || m2 == nil {
+		return m1 == m2
+	}
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			_, _, _, a1 := m1.At(p1.X+x, p1.Y+y).RGBA()
+			_, _, _, a2 := m2.At(p2.X+x, p2.Y+y).RGBA()
+			if a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (ff *fallbackFace) Close() error {
+	var err error
+	for _, f := range ff.faces {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (ff *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return ff.face(r).Glyph(dot, r)
+}
+
+func (ff *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return ff.face(r).GlyphBounds(r)
+}
+
+func (ff *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return ff.face(r).GlyphAdvance(r)
+}
+
+func (ff *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	f := ff.face(r0)
+	if f != ff.face(r1) {
+		return 0
+	}
+	return f.Kern(r0, r1)
+}
+
+func (ff *fallbackFace) Metrics() font.Metrics {
+	return ff.faces[0].Metrics()
+}
@@ -0,0 +1,144 @@
+package sdl
+
+import (
+	"unicode"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// The driver uses a tiny built-in bitmap font for the text it draws itself,
+// such as in debug overlays, so that it does not depend on the TileManager.
+const (
+	glyphWidth  = 3 // width of a glyph in pixels
+	glyphHeight = 5 // height of a glyph in pixels
+)
+
+// glyphs maps supported runes to their bitmap representation. Lowercase
+// letters are drawn as uppercase ones.
+var glyphs = map[rune][glyphHeight]string{
+	' ':  {"...", "...", "...", "...", "..."},
+	'!':  {".#.", ".#.", ".#.", "...", ".#."},
+	'"':  {"#.#", "#.#", "...", "...", "..."},
+	'#':  {"#.#", "###", "#.#", "###", "#.#"},
+	'%':  {"#.#", "..#", ".#.", "#..", "#.#"},
+	'\'': {".#.", ".#.", "...", "...", "..."},
+	'(':  {".#.", "#..", "#..", "#..", ".#."},
+	')':  {".#.", "..#", "..#", "..#", ".#."},
+	'*':  {"...", "#.#", ".#.", "#.#", "..."},
+	'+':  {"...", ".#.", "###", ".#.", "..."},
+	',':  {"...", "...", "...", ".#.", "#.."},
+	'-':  {"...", "...", "###", "...", "..."},
+	'.':  {"...", "...", "...", "...", ".#."},
+	'/':  {"..#", "..#", ".#.", "#..", "#.."},
+	'0':  {"###", "#.#", "#.#", "#.#", "###"},
+	'1':  {".#.", "##.", ".#.", ".#.", "###"},
+	'2':  {"##.", "..#", ".#.", "#..", "###"},
+	'3':  {"##.", "..#", ".#.", "..#", "##."},
+	'4':  {"#.#", "#.#", "###", "..#", "..#"},
+	'5':  {"###", "#..", "##.", "..#", "##."},
+	'6':  {".##", "#..", "###", "#.#", "###"},
+	'7':  {"###", "..#", ".#.", ".#.", ".#."},
+	'8':  {"###", "#.#", "###", "#.#", "###"},
+	'9':  {"###", "#.#", "###", "..#", "##."},
+	':':  {"...", ".#.", "...", ".#.", "..."},
+	'<':  {"..#", ".#.", "#..", ".#.", "..#"},
+	'=':  {"...", "###", "...", "###", "..."},
+	'>':  {"#..", ".#.", "..#", ".#.", "#.."},
+	'?':  {"##.", "..#", ".#.", "...", ".#."},
+	'A':  {".#.", "#.#", "###", "#.#", "#.#"},
+	'B':  {"##.", "#.#", "##.", "#.#", "##."},
+	'C':  {".##", "#..", "#..", "#..", ".##"},
+	'D':  {"##.", "#.#", "#.#", "#.#", "##."},
+	'E':  {"###", "#..", "##.", "#..", "###"},
+	'F':  {"###", "#..", "##.", "#..", "#.."},
+	'G':  {".##", "#..", "#.#", "#.#", ".##"},
+	'H':  {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I':  {"###", ".#.", ".#.", ".#.", "###"},
+	'J':  {"..#", "..#", "..#", "#.#", ".#."},
+	'K':  {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L':  {"#..", "#..", "#..", "#..", "###"},
+	'M':  {"#.#", "###", "###", "#.#", "#.#"},
+	'N':  {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O':  {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P':  {"##.", "#.#", "##.", "#..", "#.."},
+	'Q':  {".#.", "#.#", "#.#", "##.", ".##"},
+	'R':  {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S':  {".##", "#..", ".#.", "..#", "##."},
+	'T':  {"###", ".#.", ".#.", ".#.", ".#."},
+	'U':  {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V':  {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W':  {"#.#", "#.#", "###", "###", "#.#"},
+	'X':  {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y':  {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z':  {"###", "..#", ".#.", "#..", "###"},
+	'[':  {"##.", "#..", "#..", "#..", "##."},
+	']':  {".##", "..#", "..#", "..#", ".##"},
+	'_':  {"...", "...", "...", "...", "###"},
+}
+
+// textSize returns the size in pixels of a text drawn with drawText.
+func textSize(s string) (w, h int32) {
+	n := int32(len([]rune(s)))
+	if n == 0 {
+		return 0, glyphHeight
+	}
+	return n*(glyphWidth+1) - 1, glyphHeight
+}
+
+// drawText draws a text with the built-in font at the given pixel position
+// using current draw color.
+func (dr *Driver) drawText(x, y int32, s string) {
+	dr.drawTextScale(x, y, 1, s)
+}
+
+// drawTextScale is like drawText, but scales glyphs by an integer factor.
+func (dr *Driver) drawTextScale(x, y, scale int32, s string) {
+	var rects []sdl.Rect
+	for _, r := range s {
+		g, ok := glyphs[unicode.ToUpper(r)]
+		if !ok {
+			g = glyphs['?']
+		}
+		for j, line := range g {
+			for i, c := range line {
+				if c == '#' {
+					rects = append(rects, sdl.Rect{X: x + int32(i)*scale, Y: y + int32(j)*scale, W: scale, H: scale})
+				}
+			}
+		}
+		x += (glyphWidth + 1) * scale
+	}
+	if len(rects) == 0 {
+		return
+	}
+	err := dr.renderer.FillRects(rects)
+	if err != nil {
+		dr.logf("draw text: %v", err)
+	}
+}
+
+// labelSize returns the size in pixels of a label drawn with drawLabel.
+func labelSize(lines ...string) (w, h int32) {
+	for _, s := range lines {
+		lw, _ := textSize(s)
+		if lw > w {
+			w = lw
+		}
+	}
+	h = int32(len(lines))*(glyphHeight+1) - 1
+	return w + 2, h + 2
+}
+
+// drawLabel draws text lines in white over a dark translucent background.
+func (dr *Driver) drawLabel(x, y int32, lines ...string) {
+	w, h := labelSize(lines...)
+	r := dr.renderer
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	r.SetDrawColor(0, 0, 0, 160)
+	r.FillRect(&sdl.Rect{X: x, Y: y, W: w, H: h})
+	r.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+	r.SetDrawColor(255, 255, 255, 255)
+	for i, s := range lines {
+		dr.drawText(x+1, y+1+int32(i)*(glyphHeight+1), s)
+	}
+}
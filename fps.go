@@ -0,0 +1,94 @@
+package sdl
+
+import (
+	"fmt"
+	"time"
+)
+
+// fpsOverlay contains the state of the FPS overlay (see SetFPSOverlay).
+type fpsOverlay struct {
+	start  time.Time     // start of the current period
+	frames int           // number of frames presented in the period
+	total  time.Duration // total Flush duration in the period
+	max    time.Duration // maximum Flush duration in the period
+	lines  []string      // displayed text, for the previous period
+}
+
+// SetFPSOverlay enables or disables an overlay showing, in the top-right
+// corner of the window, the number of frames presented per second along
+// with the average and maximum Flush durations, updated every second. It is
+// drawn with the driver's built-in font, and can also be toggled with
+// Config.FPSKey. If the driver is already running, change will take effect
+// with next Flush so that the function is thread safe.
+func (dr *Driver) SetFPSOverlay(enabled bool) {
+	fn := func() {
+		dr.setFPSOverlay(enabled)
+	}
+	if dr.init {
+		dr.queueSet("SetFPSOverlay", fn)
+	} else {
+		fn()
+	}
+}
+
+func (dr *Driver) setFPSOverlay(enabled bool) {
+	if enabled == (dr.fps != nil) {
+		return
+	}
+	if enabled {
+		dr.fps = &fpsOverlay{start: time.Now(), lines: []string{"FPS -"}}
+	} else {
+		dr.fps = nil
+	}
+	dr.needRefresh = true
+}
+
+// addFPSFrame records a presented frame that took the given Flush duration.
+func (dr *Driver) addFPSFrame(d time.Duration) {
+	if dr.fps == nil {
+		return
+	}
+	f := dr.fps
+	f.frames++
+	f.total += d
+	if d > f.max {
+		f.max = d
+	}
+	dr.checkFPS()
+}
+
+// checkFPS updates the FPS overlay's text every second.
+func (dr *Driver) checkFPS() {
+	f := dr.fps
+	if f == nil {
+		return
+	}
+	now := time.Now()
+	period := now.Sub(f.start)
+	if period < time.Second {
+		return
+	}
+	var avg time.Duration
+	if f.frames > 0 {
+		avg = f.total / time.Duration(f.frames)
+	}
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	dr.fps = &fpsOverlay{
+		start: now,
+		lines: []string{
+			fmt.Sprintf("FPS %.1f", float64(f.frames)/period.Seconds()),
+			fmt.Sprintf("AVG %.2fMS", ms(avg)),
+			fmt.Sprintf("MAX %.2fMS", ms(f.max)),
+		},
+	}
+	dr.needRefresh = true
+}
+
+func (dr *Driver) drawFPS() {
+	lines := dr.fps.lines
+	lw, _ := labelSize(lines...)
+	w, _ := dr.windowSize()
+	dr.drawLabel(w-lw-1, 1, lines...)
+}
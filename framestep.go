@@ -0,0 +1,120 @@
+package sdl
+
+import (
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// maxPausedFrames is the maximum number of frames waiting for presentation
+// while paused. Older frames are merged together beyond that.
+const maxPausedFrames = 1024
+
+// SetFramePause pauses or resumes frame presentation, for diagnosing
+// animation glitches. While paused, flushed frames are queued instead of
+// being drawn, and can be presented one at a time with StepFrame. Resuming
+// presents all queued frames at once. If the driver is already running,
+// change will take effect with next Flush so that the function is thread
+// safe.
+func (dr *Driver) SetFramePause(paused bool) {
+	fn := func() {
+		dr.setFramePause(paused)
+	}
+	if dr.init {
+		dr.queueSet("SetFramePause", fn)
+	} else {
+		fn()
+	}
+}
+
+func (dr *Driver) setFramePause(paused bool) {
+	dr.paused = paused
+	if paused {
+		return
+	}
+	for len(dr.pausedFrames) > 0 {
+		dr.StepFrame()
+	}
+}
+
+// StepFrame presents the next frame queued while paused, if any. It should be
+// called from the main routine, for example from the Update method of a
+// gruid.Model.
+func (dr *Driver) StepFrame() {
+	if len(dr.pausedFrames) == 0 {
+		return
+	}
+	frame := dr.pausedFrames[0]
+	dr.pausedFrames = dr.pausedFrames[1:]
+	err := dr.flush(frame, time.Now())
+	if err != nil {
+		dr.handleError(err)
+	}
+}
+
+// limitFrameRate waits as necessary so that frames are not presented more
+// often than allowed by MaxFPS, and returns the new start time of the frame.
+func (dr *Driver) limitFrameRate(start time.Time) time.Time {
+	interval := time.Second / time.Duration(dr.maxFPS)
+	now := time.Now()
+	if d := dr.nextPresent.Sub(now); d > 0 {
+		time.Sleep(d)
+		now = time.Now()
+		start = now
+	}
+	dr.nextPresent = dr.nextPresent.Add(interval)
+	if dr.nextPresent.Before(now) {
+		// Late: do not try to catch up.
+		dr.nextPresent = now.Add(interval)
+	}
+	return start
+}
+
+// SetSlowMotion slows down frame presentation by the given factor, for
+// factors greater than 1: each Flush then waits so that frames are presented
+// at a fraction of their normal rate. A factor of 1 or less disables slow
+// motion. If the driver is already running, change will take effect with next
+// Flush so that the function is thread safe.
+func (dr *Driver) SetSlowMotion(factor float64) {
+	fn := func() {
+		dr.slowMotion = factor
+	}
+	if dr.init {
+		dr.queueSet("SetSlowMotion", fn)
+	} else {
+		fn()
+	}
+}
+
+// pauseFrame queues a frame for later presentation.
+func (dr *Driver) pauseFrame(frame gruid.Frame) {
+	// The frame's cells may be reused by the application, so we have
+	// to copy them.
+	frame.Cells = append([]gruid.FrameCell(nil), frame.Cells...)
+	if len(dr.pausedFrames) >= maxPausedFrames {
+		prev := &dr.pausedFrames[len(dr.pausedFrames)-1]
+		if prev.Width == frame.Width && prev.Height == frame.Height {
+			prev.Cells = append(prev.Cells, frame.Cells...)
+			prev.Time = frame.Time
+			dr.addCoalesced()
+			return
+		}
+	}
+	dr.pausedFrames = append(dr.pausedFrames, frame)
+}
+
+// slowDown waits as necessary for slow motion, given the time at which the
+// current frame was presented.
+func (dr *Driver) slowDown(t time.Time) {
+	last := dr.lastPresent
+	dr.lastPresent = t
+	if dr.slowMotion <= 1 || last.IsZero() {
+		return
+	}
+	d := time.Duration(float64(t.Sub(last)) * (dr.slowMotion - 1))
+	if d > time.Second {
+		d = time.Second
+	}
+	time.Sleep(d)
+	dr.lastPresent = time.Now()
+}
@@ -0,0 +1,71 @@
+package sdl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFramePause(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	dr.Flush(testFrame(10, 5, "a"))
+	dr.SetFramePause(true)
+	dr.Flush(testFrame(10, 5, "b"))
+	dr.Flush(testFrame(10, 5, "c"))
+	dr.Flush(testFrame(10, 5, "d"))
+	color := func() string {
+		t.Helper()
+		got := screenshot(t, dr).RGBAAt(1, 1)
+		for _, r := range "abcd" {
+			if got == testColor(r, 0) {
+				return string(r)
+			}
+		}
+		return "?"
+	}
+	if c := color(); c != "a" || len(dr.pausedFrames) != 3 {
+		t.Fatalf("paused: showing %s with %d queued frames, want a with 3", c, len(dr.pausedFrames))
+	}
+	dr.StepFrame()
+	if c := color(); c != "b" || len(dr.pausedFrames) != 2 {
+		t.Errorf("step: showing %s with %d queued frames, want b with 2", c, len(dr.pausedFrames))
+	}
+	dr.SetFramePause(false)
+	// Resuming takes effect with next Flush, which is drawn after the
+	// queued frames.
+	dr.Flush(testFrame(10, 5, "a"))
+	if c := color(); c != "a" || len(dr.pausedFrames) != 0 {
+		t.Errorf("resume: showing %s with %d queued frames, want a with 0", c, len(dr.pausedFrames))
+	}
+	if dr.grid[0].Rune != 'a' {
+		t.Errorf("grid has %q, want a", dr.grid[0].Rune)
+	}
+	// Stepping without queued frames does nothing.
+	dr.StepFrame()
+}
+
+func TestMaxFPS(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{MaxFPS: 50})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		dr.Flush(testFrame(10, 5, string(rune('a'+i))))
+	}
+	// The first frame is presented immediately, next ones every 20ms,
+	// with some tolerance for timer precision.
+	if d := time.Since(start); d < 55*time.Millisecond {
+		t.Errorf("4 frames presented in %v, want about 60ms", d)
+	}
+}
+
+func TestSlowMotion(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	dr.SetSlowMotion(3)
+	dr.Flush(testFrame(10, 5, "a"))
+	time.Sleep(10 * time.Millisecond)
+	start := time.Now()
+	dr.Flush(testFrame(10, 5, "b"))
+	// The frame came 10ms after the previous one, so Flush waits twice
+	// as long.
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("slow motion Flush took %v, want at least 20ms", d)
+	}
+}
@@ -0,0 +1,63 @@
+package sdl
+
+import (
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// GLFrame describes a rendered frame, as given to the function set with
+// SetGLFunc.
+type GLFrame struct {
+	Window  *sdl.Window  // window whose OpenGL context is current
+	Texture *sdl.Texture // offscreen texture containing the rendered frame
+	Width   int32        // drawable width of the window, in pixels
+	Height  int32        // drawable height of the window, in pixels
+}
+
+// SetGLFunc registers a callback executed just before presenting each frame
+// when an OpenGL render driver is in use (see Config.RenderDriver), so that
+// fragment shader post effects, like bloom or palette cycling, can be applied
+// over the rendered grid. The frame is rendered into an offscreen texture, as
+// with Config.Backbuffer, which is already copied into the window when the
+// callback is called: the callback can sample it, after binding it with
+// Texture.GLBind, and draw over the whole window using the current OpenGL
+// context. The callback should restore any OpenGL state it changes, as SDL
+// does not expect state changes made outside of its renderer. The callback is
+// not called with other render drivers, nor in headless mode. A nil function
+// removes the callback. If the driver is already running, change will take
+// effect with next Flush so that the function is thread safe.
+func (dr *Driver) SetGLFunc(fn func(GLFrame)) {
+	set := func() {
+		dr.glFunc = fn
+		// The backbuffer might not be up to date.
+		dr.fullRedraw = true
+		dr.needRefresh = true
+	}
+	if dr.init {
+		dr.queueSet("SetGLFunc", set)
+	} else {
+		set()
+	}
+}
+
+// callGLFunc calls the OpenGL callback, if the renderer uses OpenGL.
+func (dr *Driver) callGLFunc() {
+	r, ok := dr.renderer.(*sdlRenderer)
+	if !ok || dr.bb == nil {
+		return
+	}
+	info, err := r.GetInfo()
+	if err != nil || !strings.HasPrefix(info.Name, "opengl") {
+		return
+	}
+	// Execute pending SDL rendering commands first.
+	err = r.Flush()
+	if err != nil {
+		dr.logf("gl func: %v", err)
+		return
+	}
+	win := dr.window.(*sdl.Window)
+	w, h := win.GLGetDrawableSize()
+	dr.glFunc(GLFrame{Window: win, Texture: dr.bb.(*sdl.Texture), Width: w, Height: h})
+}
@@ -0,0 +1,103 @@
+package sdl
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// glyphCell returns the cell whose tile is used as glyph mask for a cell in
+// glyph mode: colors are ignored.
+func glyphCell(c gruid.Cell) gruid.Cell {
+	return gruid.Cell{Rune: c.Rune, Style: gruid.Style{Attrs: c.Style.Attrs}}
+}
+
+// getGlyph returns the glyph mask for a cell in glyph mode: a white image
+// whose alpha channel is the luminance of the TileManager's tile for the
+// cell with default colors.
+func (dr *Driver) getGlyph(c gruid.Cell) (image.Image, error) {
+	img, err := dr.getImage(glyphCell(c))
+	if err != nil {
+		return nil, err
+	}
+	return glyphMask(img), nil
+}
+
+// glyphMask returns the glyph mask for a tile image.
+func glyphMask(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	mask := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// Premultiplied values, so that transparent
+			// pixels are not covered either.
+			r, g, bl, _ := img.At(x, y).RGBA()
+			lum := (299*r + 587*g + 114*bl) / 1000
+			i := mask.PixOffset(x-b.Min.X, y-b.Min.Y)
+			mask.Pix[i] = 255
+			mask.Pix[i+1] = 255
+			mask.Pix[i+2] = 255
+			mask.Pix[i+3] = uint8(lum >> 8)
+		}
+	}
+	return mask
+}
+
+// copyTile copies a cell's texture into the given rectangle. In glyph mode,
+// the cell's background is filled first, and the glyph is drawn with the
+// cell's foreground color.
+func (dr *Driver) copyTile(c gruid.Cell, tx texture, rect *sdl.Rect) error {
+	if dr.glyphColors == nil {
+		return dr.copyTransformed(c, tx, rect)
+	}
+	st := c.Style
+	st.Attrs &^= dr.ghostAttr
+	fg, bg := dr.glyphColors(st)
+	r, g, b := dr.filterRGB(rgb(bg))
+	err := dr.renderer.SetDrawColor(r, g, b, 255)
+	if err == nil {
+		err = dr.renderer.FillRect(rect)
+	}
+	if err != nil {
+		return fmt.Errorf("glyph background: %v", err)
+	}
+	r, g, b = dr.filterRGB(rgb(fg))
+	err = tx.SetColorMod(r, g, b)
+	if err != nil {
+		return fmt.Errorf("glyph color: %v", err)
+	}
+	return dr.copyTransformed(c, tx, rect)
+}
+
+// copyTransformed copies a cell's texture into the given rectangle, with
+// the cell's transformation, if any (see TileTransformer).
+func (dr *Driver) copyTransformed(c gruid.Cell, tx texture, rect *sdl.Rect) error {
+	if angle, flip, ok := dr.transform(c); ok {
+		if math.Mod(angle, 90) != 0 && dr.glyphColors == nil {
+			// The rotated tile does not cover the whole cell.
+			err := dr.renderer.SetDrawColor(0, 0, 0, 255)
+			if err == nil {
+				err = dr.renderer.FillRect(rect)
+			}
+			if err != nil {
+				return fmt.Errorf("transform: %v", err)
+			}
+		}
+		return dr.copyTextureEx(tx, rect, angle, flip)
+	}
+	return dr.copyTexture(tx, rect)
+}
+
+// rgb returns the 8 bits color components of an opaque color, or black if
+// nil.
+func rgb(c color.Color) (r, g, b uint8) {
+	if c == nil {
+		return 0, 0, 0
+	}
+	cr, cg, cb, _ := c.RGBA()
+	return uint8(cr >> 8), uint8(cg >> 8), uint8(cb >> 8)
+}
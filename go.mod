@@ -1,6 +1,6 @@
 module github.com/anaseto/gruid-sdl
 
-go 1.15
+go 1.16
 
 require (
 	github.com/anaseto/gruid v0.21.0
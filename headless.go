@@ -0,0 +1,533 @@
+package sdl
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sync"
+	"time"
+
+	xdraw "golang.org/x/image/draw"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// headless is a backend that does not require a display: rendering is done
+// into an in-memory image, and events are only those explicitly queued.
+type headless struct {
+	mu     sync.Mutex // protects events, as pushEvent is thread safe
+	events []sdl.Event
+
+	userEvents uint32     // number of registered event types
+	mod        sdl.Keymod // keyboard modifier state
+}
+
+func (hl *headless) init() error {
+	return nil
+}
+
+func (hl *headless) quit() {
+	hl.mu.Lock()
+	hl.events = nil
+	hl.mu.Unlock()
+}
+
+func (hl *headless) createWindow(title string, w, h int32, flags uint32) (window, error) {
+	return &headlessWindow{title: title, w: w, h: h}, nil
+}
+
+func (hl *headless) createRenderer(win window, driver string, flags uint32) (renderer, error) {
+	return &headlessRenderer{win: win.(*headlessWindow)}, nil
+}
+
+func (hl *headless) pollEvent() sdl.Event {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	if len(hl.events) == 0 {
+		return nil
+	}
+	ev := hl.events[0]
+	hl.events = hl.events[1:]
+	return ev
+}
+
+func (hl *headless) waitEvent(timeout int) sdl.Event {
+	hl.mu.Lock()
+	n := len(hl.events)
+	hl.mu.Unlock()
+	if n == 0 {
+		// No events can arrive while waiting, so we just sleep for
+		// some time to avoid busy loops.
+		if timeout < 0 || timeout > 10 {
+			timeout = 10
+		}
+		time.Sleep(time.Duration(timeout) * time.Millisecond)
+	}
+	return hl.pollEvent()
+}
+
+func (hl *headless) displayDPI(win window) (float32, error) {
+	return 0, nil
+}
+
+func (hl *headless) refreshRate(win window) (int, error) {
+	return 0, nil
+}
+
+func (hl *headless) modState() sdl.Keymod {
+	return hl.mod
+}
+
+func (hl *headless) startTextInput() {}
+
+func (hl *headless) stopTextInput() {}
+
+func (hl *headless) setCursor(img image.Image, hot image.Point) error {
+	return nil
+}
+
+func (hl *headless) showCursor(show bool) error {
+	return nil
+}
+
+// headlessUserEvent is the first event type returned by registerEvent.
+const headlessUserEvent = sdl.USEREVENT
+
+func (hl *headless) registerEvent() (uint32, error) {
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	hl.userEvents++
+	return headlessUserEvent + hl.userEvents - 1, nil
+}
+
+func (hl *headless) pushEvent(ev sdl.Event) error {
+	hl.mu.Lock()
+	hl.events = append(hl.events, ev)
+	hl.mu.Unlock()
+	return nil
+}
+
+// inject queues events as if they had been received from SDL, and sets the
+// keyboard modifier state reported with them, so that the driver's event
+// handling can be tested.
+func (hl *headless) inject(mod sdl.Keymod, events ...sdl.Event) {
+	hl.mu.Lock()
+	hl.events = append(hl.events, events...)
+	hl.mu.Unlock()
+	hl.mod = mod
+}
+
+func (hl *headless) captureDevices() ([]string, error) {
+	return nil, nil
+}
+
+func (hl *headless) openCapture(device string, rate int) (captureDevice, int, error) {
+	return nil, 0, errors.New("no audio devices in headless mode")
+}
+
+func (hl *headless) initControllers() error {
+	return nil
+}
+
+func (hl *headless) openController(index int) error {
+	return nil
+}
+
+func (hl *headless) setHint(name, value string) error {
+	return nil
+}
+
+func (hl *headless) lastError() error {
+	return nil
+}
+
+// headlessWindow implements window for the headless backend.
+type headlessWindow struct {
+	title string
+	w, h  int32
+	x, y  int32
+}
+
+func (win *headlessWindow) GetSize() (int32, int32) {
+	return win.w, win.h
+}
+
+func (win *headlessWindow) SetSize(w, h int32) {
+	win.w, win.h = w, h
+}
+
+func (win *headlessWindow) SetTitle(title string) {
+	win.title = title
+}
+
+func (win *headlessWindow) SetIcon(icon *sdl.Surface) {}
+
+func (win *headlessWindow) SetFullscreen(flags uint32) error {
+	return nil
+}
+
+func (win *headlessWindow) SetResizable(resizable bool) {}
+
+func (win *headlessWindow) SetWindowOpacity(opacity float32) error {
+	return nil
+}
+
+func (win *headlessWindow) GetPosition() (int32, int32) {
+	return win.x, win.y
+}
+
+func (win *headlessWindow) SetPosition(x, y int32) {
+	win.x, win.y = x, y
+}
+
+func (win *headlessWindow) Destroy() error {
+	return nil
+}
+
+// headlessRenderer implements renderer by drawing into an image with the
+// same size as the window.
+type headlessRenderer struct {
+	win    *headlessWindow
+	canvas *image.RGBA
+	scaleX float32
+	scaleY float32
+	color  color.NRGBA
+	blend  sdl.BlendMode
+	tx     *headlessTexture // rendering target, if not the canvas
+	lw, lh int32            // logical size, if any, with integer scaling
+	clip   *image.Rectangle // clipping rectangle in canvas coordinates, if any
+}
+
+// target returns the current rendering target. The canvas is first resized
+// if the window size changed.
+func (r *headlessRenderer) target() *image.RGBA {
+	if r.tx != nil {
+		return r.tx.img.(*image.RGBA)
+	}
+	rect := image.Rect(0, 0, int(r.win.w), int(r.win.h))
+	if r.canvas == nil || r.canvas.Rect != rect {
+		canvas := image.NewRGBA(rect)
+		if r.canvas != nil {
+			draw.Draw(canvas, rect, r.canvas, image.Point{}, draw.Src)
+		}
+		r.canvas = canvas
+	}
+	return r.canvas
+}
+
+// dst returns the current rendering target, restricted to the clipping
+// rectangle, if any.
+func (r *headlessRenderer) dst() *image.RGBA {
+	if r.clip == nil {
+		return r.target()
+	}
+	return r.target().SubImage(*r.clip).(*image.RGBA)
+}
+
+// scale converts a rectangle in rendering coordinates into canvas
+// coordinates.
+func (r *headlessRenderer) scale(rect *sdl.Rect) image.Rectangle {
+	if rect == nil {
+		return r.target().Rect
+	}
+	sx, sy := r.scaleX, r.scaleY
+	if sx <= 0.1 || sy <= 0.1 || r.tx != nil {
+		sx, sy = 1, 1
+	}
+	var off image.Point
+	if r.lw > 0 && r.lh > 0 && r.tx == nil {
+		n := r.win.w / r.lw
+		if m := r.win.h / r.lh; m < n {
+			n = m
+		}
+		if n < 1 {
+			n = 1
+		}
+		sx, sy = float32(n), float32(n)
+		off = image.Pt(int(r.win.w-r.lw*n)/2, int(r.win.h-r.lh*n)/2)
+	}
+	return image.Rect(int(float32(rect.X)*sx), int(float32(rect.Y)*sy),
+		int(float32(rect.X+rect.W)*sx), int(float32(rect.Y+rect.H)*sy)).Add(off)
+}
+
+func (r *headlessRenderer) SetScale(scaleX, scaleY float32) error {
+	r.scaleX, r.scaleY = scaleX, scaleY
+	return nil
+}
+
+func (r *headlessRenderer) SetDrawColor(red, green, blue, alpha uint8) error {
+	r.color = color.NRGBA{R: red, G: green, B: blue, A: alpha}
+	return nil
+}
+
+func (r *headlessRenderer) SetDrawBlendMode(bm sdl.BlendMode) error {
+	r.blend = bm
+	return nil
+}
+
+func (r *headlessRenderer) FillRect(rect *sdl.Rect) error {
+	switch r.blend {
+	case sdl.BLENDMODE_ADD:
+		r.addRect(r.scale(rect))
+		return nil
+	case brightenBlendMode():
+		r.brightenRect(r.scale(rect))
+		return nil
+	}
+	op := draw.Src
+	if r.blend == sdl.BLENDMODE_BLEND {
+		op = draw.Over
+	}
+	draw.Draw(r.dst(), r.scale(rect), image.NewUniform(r.color), image.Point{}, op)
+	return nil
+}
+
+// addRect adds the draw color to a canvas rectangle, as with additive
+// blending.
+func (r *headlessRenderer) addRect(rect image.Rectangle) {
+	canvas := r.dst()
+	rect = rect.Intersect(canvas.Rect)
+	a := uint32(r.color.A)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			i := canvas.PixOffset(x, y)
+			for j, v := range [3]uint8{r.color.R, r.color.G, r.color.B} {
+				s := uint32(canvas.Pix[i+j]) + uint32(v)*a/255
+				if s > 255 {
+					s = 255
+				}
+				canvas.Pix[i+j] = uint8(s)
+			}
+		}
+	}
+}
+
+// brightenRect multiplies a canvas rectangle's colors by one plus the draw
+// color, as with brightenBlendMode.
+func (r *headlessRenderer) brightenRect(rect image.Rectangle) {
+	canvas := r.dst()
+	rect = rect.Intersect(canvas.Rect)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			i := canvas.PixOffset(x, y)
+			for j, v := range [3]uint8{r.color.R, r.color.G, r.color.B} {
+				d := uint32(canvas.Pix[i+j])
+				s := d + d*uint32(v)/255
+				if s > 255 {
+					s = 255
+				}
+				canvas.Pix[i+j] = uint8(s)
+			}
+		}
+	}
+}
+
+func (r *headlessRenderer) FillRects(rects []sdl.Rect) error {
+	for i := range rects {
+		r.FillRect(&rects[i])
+	}
+	return nil
+}
+
+func (r *headlessRenderer) SetClipRect(rect *sdl.Rect) error {
+	if rect == nil {
+		r.clip = nil
+		return nil
+	}
+	clip := r.scale(rect)
+	r.clip = &clip
+	return nil
+}
+
+// Clear fills the whole rendering target, ignoring the clipping rectangle
+// and blend mode, as SDL does.
+func (r *headlessRenderer) Clear() error {
+	target := r.target()
+	draw.Draw(target, target.Rect, image.NewUniform(r.color), image.Point{}, draw.Src)
+	return nil
+}
+
+func (r *headlessRenderer) Present() {}
+
+func (r *headlessRenderer) Destroy() error {
+	r.canvas = nil
+	return nil
+}
+
+func (r *headlessRenderer) GetInfo() (sdl.RendererInfo, error) {
+	return sdl.RendererInfo{Name: "headless"}, nil
+}
+
+func (r *headlessRenderer) createTexture(img image.Image) (texture, error) {
+	return &headlessTexture{img: img, alpha: 255}, nil
+}
+
+// headlessStaged implements staged for the headless backend.
+type headlessStaged struct {
+	img image.Image
+}
+
+func (st headlessStaged) free() {}
+
+func (r *headlessRenderer) stageTexture(img image.Image) (staged, error) {
+	return headlessStaged{img}, nil
+}
+
+func (r *headlessRenderer) uploadTexture(st staged) (texture, error) {
+	return r.createTexture(st.(headlessStaged).img)
+}
+
+func (r *headlessRenderer) setTextureFormats(opaque, alpha uint32) {}
+
+func (r *headlessRenderer) setLogicalSize(w, h int32, integer bool) error {
+	r.lw, r.lh = w, h
+	return nil
+}
+
+func (r *headlessRenderer) createAtlas(w, h int32) (texture, error) {
+	return &headlessTexture{img: image.NewRGBA(image.Rect(0, 0, int(w), int(h))), alpha: 255}, nil
+}
+
+func (r *headlessRenderer) createStreaming(w, h int32) (texture, error) {
+	return r.createAtlas(w, h)
+}
+
+func (r *headlessRenderer) updateTexture(tx texture, rect sdl.Rect, img *image.NRGBA) error {
+	dst := tx.(*headlessTexture).img.(*image.RGBA)
+	draw.Draw(dst, image.Rect(int(rect.X), int(rect.Y), int(rect.X+rect.W), int(rect.Y+rect.H)), img, img.Rect.Min, draw.Src)
+	return nil
+}
+
+func (r *headlessRenderer) createTarget(w, h int32) (texture, error) {
+	return &headlessTexture{img: image.NewRGBA(image.Rect(0, 0, int(w), int(h))), alpha: 255}, nil
+}
+
+func (r *headlessRenderer) setTarget(tx texture) error {
+	if tx == nil {
+		r.tx = nil
+		return nil
+	}
+	r.tx = tx.(*headlessTexture)
+	return nil
+}
+
+func (r *headlessRenderer) copy(tx texture, src, dst *sdl.Rect) error {
+	htx := tx.(*headlessTexture)
+	img := htx.modulated()
+	sr := img.Bounds()
+	if src != nil {
+		sr = image.Rect(int(src.X), int(src.Y), int(src.X+src.W), int(src.Y+src.H)).Add(sr.Min)
+	}
+	var opts *xdraw.Options
+	if htx.blend == sdl.BLENDMODE_BLEND && htx.alpha < 255 {
+		opts = &xdraw.Options{SrcMask: image.NewUniform(color.Alpha{A: htx.alpha})}
+	}
+	xdraw.NearestNeighbor.Scale(r.dst(), r.scale(dst), img, sr, draw.Over, opts)
+	return nil
+}
+
+func (r *headlessRenderer) copyEx(tx texture, src, dst *sdl.Rect, angle float64, flip sdl.RendererFlip) error {
+	htx := tx.(*headlessTexture)
+	img := htx.modulated()
+	sr := img.Bounds()
+	if src != nil {
+		sr = image.Rect(int(src.X), int(src.Y), int(src.X+src.W), int(src.Y+src.H)).Add(sr.Min)
+	}
+	dr := r.scale(dst)
+	if dr.Empty() || sr.Empty() {
+		return nil
+	}
+	// Sample the source with nearest neighbor for each destination pixel,
+	// applying the inverse transformation.
+	out := image.NewNRGBA(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	sin, cos := math.Sincos(-angle * math.Pi / 180)
+	w, h := float64(dr.Dx()), float64(dr.Dy())
+	for y := 0; y < dr.Dy(); y++ {
+		for x := 0; x < dr.Dx(); x++ {
+			px, py := float64(x)+0.5-w/2, float64(y)+0.5-h/2
+			qx, qy := px*cos-py*sin+w/2, px*sin+py*cos+h/2
+			if qx < 0 || qx >= w || qy < 0 || qy >= h {
+				continue
+			}
+			if flip&sdl.FLIP_HORIZONTAL != 0 {
+				qx = w - qx
+			}
+			if flip&sdl.FLIP_VERTICAL != 0 {
+				qy = h - qy
+			}
+			sx := sr.Min.X + int(qx*float64(sr.Dx())/w)
+			sy := sr.Min.Y + int(qy*float64(sr.Dy())/h)
+			if sx >= sr.Max.X {
+				sx = sr.Max.X - 1
+			}
+			if sy >= sr.Max.Y {
+				sy = sr.Max.Y - 1
+			}
+			out.Set(x, y, img.At(sx, sy))
+		}
+	}
+	var mask image.Image
+	if htx.blend == sdl.BLENDMODE_BLEND && htx.alpha < 255 {
+		mask = image.NewUniform(color.Alpha{A: htx.alpha})
+	}
+	draw.DrawMask(r.dst(), dr, out, image.Point{}, mask, image.Point{}, draw.Over)
+	return nil
+}
+
+func (r *headlessRenderer) readPixels() (*image.RGBA, error) {
+	canvas := r.target()
+	img := image.NewRGBA(canvas.Rect)
+	copy(img.Pix, canvas.Pix)
+	return img, nil
+}
+
+// headlessTexture implements texture for the headless backend.
+type headlessTexture struct {
+	img   image.Image
+	alpha uint8
+	blend sdl.BlendMode
+	mod   *color.RGBA // color modulation, if any
+}
+
+func (tx *headlessTexture) SetAlphaMod(alpha uint8) error {
+	tx.alpha = alpha
+	return nil
+}
+
+func (tx *headlessTexture) SetColorMod(r, g, b uint8) error {
+	tx.mod = &color.RGBA{R: r, G: g, B: b, A: 255}
+	if r == 255 && g == 255 && b == 255 {
+		tx.mod = nil
+	}
+	return nil
+}
+
+// modulated returns the texture's image with color modulation applied.
+func (tx *headlessTexture) modulated() image.Image {
+	if tx.mod == nil {
+		return tx.img
+	}
+	b := tx.img.Bounds()
+	img := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(tx.img.At(x, y)).(color.NRGBA)
+			c.R = uint8(uint32(c.R) * uint32(tx.mod.R) / 255)
+			c.G = uint8(uint32(c.G) * uint32(tx.mod.G) / 255)
+			c.B = uint8(uint32(c.B) * uint32(tx.mod.B) / 255)
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func (tx *headlessTexture) SetBlendMode(bm sdl.BlendMode) error {
+	tx.blend = bm
+	return nil
+}
+
+func (tx *headlessTexture) Destroy() error {
+	return nil
+}
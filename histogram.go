@@ -0,0 +1,117 @@
+package sdl
+
+import (
+	"sort"
+	"time"
+)
+
+// histogramSize is the number of recent frames kept for the frame time
+// histogram.
+const histogramSize = 256
+
+// HistogramBounds contains the upper bounds of the frame time histogram
+// buckets. The last bucket has no upper bound.
+var HistogramBounds = [...]time.Duration{
+	1 * time.Millisecond,
+	2 * time.Millisecond,
+	4 * time.Millisecond,
+	8 * time.Millisecond,
+	16 * time.Millisecond,
+	33 * time.Millisecond,
+	66 * time.Millisecond,
+	133 * time.Millisecond,
+}
+
+// Histogram describes the distribution of frame times over recent frames, as
+// returned by Driver.FrameHistogram.
+type Histogram struct {
+	Frames  int                           // number of frames in the histogram
+	Buckets [len(HistogramBounds) + 1]int // frame counts per bucket (see HistogramBounds)
+	P50     time.Duration                 // median frame time
+	P90     time.Duration                 // 90th percentile frame time
+	P99     time.Duration                 // 99th percentile frame time
+	Max     time.Duration                 // maximum frame time
+	Present [len(HistogramBounds) + 1]int // Present durations counts per bucket
+}
+
+// MsgFrameStall is reported when a frame took longer than the configured
+// frame budget (see Config.FrameBudget).
+type MsgFrameStall struct {
+	Duration time.Duration // total duration of the Flush
+	Draw     time.Duration // time spent drawing tiles (including TileManager calls)
+	Present  time.Duration // time spent presenting the frame
+	Budget   time.Duration // configured frame budget
+	Time     time.Time     // time when the frame was presented
+}
+
+// frameSample contains timings for a frame.
+type frameSample struct {
+	total   time.Duration
+	present time.Duration
+}
+
+func (s *stats) addSample(fs frameSample) {
+	s.mu.Lock()
+	if len(s.samples) < histogramSize {
+		s.samples = append(s.samples, fs)
+	} else {
+		s.samples[s.next] = fs
+		s.next = (s.next + 1) % histogramSize
+	}
+	s.mu.Unlock()
+}
+
+func (s *stats) histogram() Histogram {
+	s.mu.Lock()
+	totals := make([]time.Duration, 0, len(s.samples))
+	var h Histogram
+	for _, fs := range s.samples {
+		totals = append(totals, fs.total)
+		h.Buckets[bucket(fs.total)]++
+		h.Present[bucket(fs.present)]++
+	}
+	s.mu.Unlock()
+	h.Frames = len(totals)
+	if h.Frames == 0 {
+		return h
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i] < totals[j] })
+	h.P50 = totals[h.Frames*50/100]
+	h.P90 = totals[h.Frames*90/100]
+	h.P99 = totals[h.Frames*99/100]
+	h.Max = totals[h.Frames-1]
+	return h
+}
+
+// bucket returns the histogram bucket index for a duration.
+func bucket(d time.Duration) int {
+	for i, b := range HistogramBounds {
+		if d <= b {
+			return i
+		}
+	}
+	return len(HistogramBounds)
+}
+
+// FrameHistogram returns the distribution of frame times over recent frames.
+// It is safe to call it concurrently.
+func (dr *Driver) FrameHistogram() Histogram {
+	return dr.stats.histogram()
+}
+
+// checkBudget reports a stall if the frame exceeded the configured budget,
+// and returns whether it did.
+func (dr *Driver) checkBudget(msg MsgFrameStall) bool {
+	if dr.frameBudget <= 0 || msg.Duration <= dr.frameBudget {
+		return false
+	}
+	msg.Budget = dr.frameBudget
+	dr.stats.addStall()
+	if msg.Time.Sub(dr.lastStallLog) >= time.Second {
+		// Avoid flooding the logs when stalls are frequent.
+		dr.lastStallLog = msg.Time
+		dr.logf("frame stall: %v > %v (draw: %v, present: %v)", msg.Duration, msg.Budget, msg.Draw, msg.Present)
+	}
+	dr.msgs = append(dr.msgs, msg)
+	return true
+}
@@ -0,0 +1,45 @@
+package sdl
+
+import (
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// FrameHooks contains optional callbacks for instrumenting frame rendering,
+// such as for profiling or frame pacing. They are called on the main routine,
+// from Flush.
+type FrameHooks struct {
+	// BeforeFlush is called before drawing a frame, with the frame passed
+	// to Flush and the frame's start time. It is not called for frames that
+	// are not drawn: while paused (see SetFramePause) or suspended, or when
+	// nothing changed. The start time is the one at which Flush was called,
+	// unless Flush had to wait because of Config.MaxFPS, in which case it is
+	// the end of the wait.
+	BeforeFlush func(frame gruid.Frame, start time.Time)
+
+	// AfterPresent is called after the frame has been presented.
+	AfterPresent func(FrameInfo)
+}
+
+// FrameInfo contains timing information about a presented frame.
+type FrameInfo struct {
+	Start   time.Time     // frame start time, as given to BeforeFlush
+	Draw    time.Duration // time spent drawing cells
+	Present time.Duration // time spent presenting the frame
+	Cells   int           // number of drawn cells, including redrawn ones
+}
+
+// SetFrameHooks registers frame rendering callbacks, replacing previous ones.
+// If the driver is already running, change will take effect with next Flush
+// so that the function is thread safe.
+func (dr *Driver) SetFrameHooks(hooks FrameHooks) {
+	fn := func() {
+		dr.hooks = hooks
+	}
+	if dr.init {
+		dr.queueSet("SetFrameHooks", fn)
+	} else {
+		fn()
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !sdlimage
+// +build !sdlimage
+
+package sdl
+
+import (
+	"bytes"
+	"image"
+)
+
+// decodeImage decodes an image using the standard library's image package.
+func decodeImage(b []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(b))
+	return img, err
+}
+
+// unwrapImage is only used with the sdlimage build tag.
+func unwrapImage(im image.Image) image.Image {
+	return im
+}
@@ -0,0 +1,63 @@
+//go:build sdlimage
+// +build sdlimage
+
+package sdl
+
+import (
+	"image"
+
+	"github.com/veandco/go-sdl2/img"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// sdlImage is an image decoded with SDL_image. Its pixels are in the same
+// format as SDL surfaces in PIXELFORMAT_RGBA32 format, so that surfaces can
+// be created from it by simply copying them.
+type sdlImage struct {
+	*image.NRGBA
+}
+
+// SubImage returns an image representing the portion of the image visible
+// through r.
+func (m *sdlImage) SubImage(r image.Rectangle) image.Image {
+	return &sdlImage{m.NRGBA.SubImage(r).(*image.NRGBA)}
+}
+
+// decodeImage decodes an image using the SDL_image library, which supports
+// formats like PNG, JPG or WebP, depending on how the library was built.
+func decodeImage(b []byte) (image.Image, error) {
+	src, err := sdl.RWFromMem(b)
+	if err != nil {
+		return nil, err
+	}
+	sf, err := img.LoadRW(src, true)
+	if err != nil {
+		return nil, err
+	}
+	defer sf.Free()
+	cv, err := sf.ConvertFormat(uint32(sdl.PIXELFORMAT_RGBA32), 0)
+	if err != nil {
+		return nil, err
+	}
+	defer cv.Free()
+	w, h := int(cv.W), int(cv.H)
+	m := image.NewNRGBA(image.Rect(0, 0, w, h))
+	if err := cv.Lock(); err != nil {
+		return nil, err
+	}
+	defer cv.Unlock()
+	pix, pitch := cv.Pixels(), int(cv.Pitch)
+	for y := 0; y < h; y++ {
+		copy(m.Pix[y*m.Stride:y*m.Stride+4*w], pix[y*pitch:])
+	}
+	return &sdlImage{m}, nil
+}
+
+// unwrapImage returns the underlying *image.NRGBA of images decoded with
+// SDL_image, so that their pixels can be used without conversion.
+func unwrapImage(im image.Image) image.Image {
+	if m, ok := im.(*sdlImage); ok {
+		return m.NRGBA
+	}
+	return im
+}
@@ -0,0 +1,243 @@
+package sdl
+
+import (
+	"strings"
+	"time"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Special keys of the virtual keyboard.
+const (
+	vkShift = "SHIFT"
+	vkSpace = "SPACE"
+	vkDel   = "DEL"
+	vkOK    = "OK"
+)
+
+// vkRows is the layout of the virtual keyboard.
+var vkRows = [][]string{
+	strings.Split("1234567890", ""),
+	strings.Split("qwertyuiop", ""),
+	append(strings.Split("asdfghjkl", ""), "-"),
+	append(strings.Split("zxcvbnm", ""), ".", ",", "'"),
+	{vkShift, vkSpace, vkDel, vkOK},
+}
+
+const (
+	vkScale = 2 // scale of the built-in font for key labels
+	vkPad   = 3 // padding around key labels, in pixels
+)
+
+// virtualKeyboard holds the state of the on-screen keyboard.
+type virtualKeyboard struct {
+	active     bool // whether a text input widget is active
+	controller bool // whether a controller is in use
+	shift      bool
+	row, col   int
+}
+
+// SetVirtualKeyboard informs the driver whether a text input widget is
+// currently active in the application. When the VirtualKeyboard
+// configuration option is set, and a game controller is being used, an
+// on-screen keyboard is shown while text input is active. It can be navigated
+// with the d-pad, and emits gruid.MsgKeyDown messages:
+//
+//	A       type the selected key
+//	B       Backspace
+//	X       space
+//	Y       toggle shift
+//	Start   Enter
+//	Back    Escape
+//
+// It should be called from the main routine, for example from the Update
+// method of a gruid.Model.
+func (dr *Driver) SetVirtualKeyboard(active bool) {
+	if dr.vkbd.active == active {
+		return
+	}
+	dr.vkbd.active = active
+	dr.vkbd.row, dr.vkbd.col = 0, 0
+	if dr.vkbd.controller {
+		dr.needRefresh = true
+	}
+}
+
+// showVirtualKeyboard reports whether the virtual keyboard should be drawn.
+func (dr *Driver) showVirtualKeyboard() bool {
+	return dr.virtualKeyboard && dr.vkbd.active && dr.vkbd.controller
+}
+
+// pollControllerEvent handles game controller events, for the virtual
+// keyboard.
+func (dr *Driver) pollControllerEvent(event sdl.Event) gruid.Msg {
+	switch ev := event.(type) {
+	case *sdl.ControllerDeviceEvent:
+		if ev.Type == sdl.CONTROLLERDEVICEADDED {
+			err := dr.backend.openController(int(ev.Which))
+			if err != nil {
+				dr.logf("controller: %v", err)
+			}
+		}
+	case *sdl.ControllerButtonEvent:
+		if ev.Type != sdl.CONTROLLERBUTTONDOWN {
+			return nil
+		}
+		if !dr.vkbd.controller {
+			dr.vkbd.controller = true
+			dr.needRefresh = dr.needRefresh || dr.vkbd.active
+		}
+		if !dr.showVirtualKeyboard() {
+			return nil
+		}
+		return dr.virtualKeyboardButton(ev.Button)
+	case *sdl.KeyboardEvent:
+		if dr.vkbd.controller && ev.Type == sdl.KEYDOWN {
+			// The user went back to the physical keyboard.
+			dr.vkbd.controller = false
+			if dr.vkbd.active {
+				dr.needRefresh = true
+			}
+		}
+	}
+	return nil
+}
+
+// virtualKeyboardButton handles a controller button press while the virtual
+// keyboard is shown.
+func (dr *Driver) virtualKeyboardButton(button uint8) gruid.Msg {
+	vk := &dr.vkbd
+	var key gruid.Key
+	switch button {
+	case sdl.CONTROLLER_BUTTON_DPAD_UP:
+		vk.row = (vk.row + len(vkRows) - 1) % len(vkRows)
+	case sdl.CONTROLLER_BUTTON_DPAD_DOWN:
+		vk.row = (vk.row + 1) % len(vkRows)
+	case sdl.CONTROLLER_BUTTON_DPAD_LEFT:
+		n := len(vkRows[vk.row])
+		vk.col = (vk.col + n - 1) % n
+	case sdl.CONTROLLER_BUTTON_DPAD_RIGHT:
+		vk.col = (vk.col + 1) % len(vkRows[vk.row])
+	case sdl.CONTROLLER_BUTTON_A:
+		key = dr.virtualKey()
+	case sdl.CONTROLLER_BUTTON_B:
+		key = gruid.KeyBackspace
+	case sdl.CONTROLLER_BUTTON_X:
+		key = gruid.KeySpace
+	case sdl.CONTROLLER_BUTTON_Y:
+		vk.shift = !vk.shift
+	case sdl.CONTROLLER_BUTTON_START:
+		key = gruid.KeyEnter
+	case sdl.CONTROLLER_BUTTON_BACK:
+		key = gruid.KeyEscape
+	default:
+		return nil
+	}
+	if vk.col >= len(vkRows[vk.row]) {
+		vk.col = len(vkRows[vk.row]) - 1
+	}
+	dr.needRefresh = true
+	if key == "" {
+		return nil
+	}
+	return gruid.MsgKeyDown{Key: key, Time: time.Now()}
+}
+
+// virtualKey returns the key for the selected virtual keyboard key, if any.
+func (dr *Driver) virtualKey() gruid.Key {
+	vk := &dr.vkbd
+	switch s := vkRows[vk.row][vk.col]; s {
+	case vkShift:
+		vk.shift = !vk.shift
+		return ""
+	case vkSpace:
+		return gruid.KeySpace
+	case vkDel:
+		return gruid.KeyBackspace
+	case vkOK:
+		return gruid.KeyEnter
+	default:
+		if vk.shift {
+			s = strings.ToUpper(s)
+		}
+		return gruid.Key(s)
+	}
+}
+
+// vkKeySize returns the size in pixels of a virtual keyboard key.
+func vkKeySize(s string) (w, h int32) {
+	if len(s) == 1 {
+		// Single character keys all have the same width.
+		s = "W"
+	}
+	w, h = textSize(s)
+	return w*vkScale + 2*vkPad, h*vkScale + 2*vkPad
+}
+
+// drawVirtualKeyboard draws the virtual keyboard centered at the bottom of
+// the grid.
+func (dr *Driver) drawVirtualKeyboard() {
+	var kw, kh int32
+	for _, row := range vkRows {
+		var w int32
+		for _, s := range row {
+			sw, sh := vkKeySize(s)
+			w += sw + 1
+			kh = sh
+		}
+		if w > kw {
+			kw = w
+		}
+	}
+	kw++
+	kh++
+	hint := "A:TYPE B:DEL X:SPACE Y:SHIFT START:OK"
+	if hw, _ := textSize(hint); hw+2 > kw {
+		kw = hw + 2
+	}
+	h := kh*int32(len(vkRows)) + 1 + (glyphHeight + 2)
+	gw, gh := dr.width*dr.tw, dr.height*dr.th
+	x0, y0 := (gw-kw)/2, gh-h
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	r := dr.renderer
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	r.SetDrawColor(0, 0, 0, 200)
+	r.FillRect(&sdl.Rect{X: x0, Y: y0, W: kw, H: h})
+	r.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+	r.SetDrawColor(160, 160, 160, 255)
+	dr.drawText(x0+1, y0+1, hint)
+	y := y0 + glyphHeight + 3
+	for i, row := range vkRows {
+		var w int32
+		for _, s := range row {
+			sw, _ := vkKeySize(s)
+			w += sw + 1
+		}
+		x := x0 + (kw-w)/2
+		for j, s := range row {
+			sw, sh := vkKeySize(s)
+			selected := i == dr.vkbd.row && j == dr.vkbd.col
+			if selected {
+				r.SetDrawColor(80, 140, 255, 255)
+			} else {
+				r.SetDrawColor(48, 48, 48, 255)
+			}
+			r.FillRect(&sdl.Rect{X: x, Y: y, W: sw, H: sh})
+			if s == vkShift && dr.vkbd.shift {
+				r.SetDrawColor(255, 255, 0, 255)
+			} else {
+				r.SetDrawColor(255, 255, 255, 255)
+			}
+			lw, _ := textSize(s)
+			dr.drawTextScale(x+(sw-lw*vkScale)/2, y+vkPad, vkScale, s)
+			x += sw + 1
+		}
+		y += kh
+	}
+}
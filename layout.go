@@ -0,0 +1,131 @@
+package sdl
+
+import (
+	"math"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Layout describes how grid cells are placed in the window.
+type Layout int
+
+// Available layouts.
+const (
+	GridLayout       Layout = iota // rectangular grid (default)
+	HexRowsLayout                  // odd rows offset by half a tile to the right
+	HexColumnsLayout               // odd columns offset by half a tile downwards
+	IsometricLayout                // diamond-shaped isometric projection
+)
+
+// cellRect returns the rectangle of the cell at the given grid position, in
+// rendering coordinates.
+func (dr *Driver) cellRect(x, y int) sdl.Rect {
+	rect := sdl.Rect{X: int32(x) * dr.tw, Y: int32(y) * dr.th, W: dr.tw, H: dr.th}
+	switch dr.layout {
+	case HexRowsLayout:
+		if y%2 == 1 {
+			rect.X += dr.tw / 2
+		}
+	case HexColumnsLayout:
+		if x%2 == 1 {
+			rect.Y += dr.th / 2
+		}
+	case IsometricLayout:
+		rect.X = (int32(x-y) + dr.height - 1) * dr.tw / 2
+		rect.Y = int32(x+y) * dr.th / 2
+	}
+	rect.X += int32(dr.padding.X)
+	rect.Y += int32(dr.padding.Y)
+	return rect
+}
+
+// gridSize returns the size of the area covered by the grid, including
+// padding, in rendering coordinates.
+func (dr *Driver) gridSize() (int32, int32) {
+	r := dr.gridRect()
+	return r.W + 2*r.X, r.H + 2*r.Y
+}
+
+// gridRect returns the area covered by the grid cells, in rendering
+// coordinates.
+func (dr *Driver) gridRect() sdl.Rect {
+	w, h := dr.width*dr.tw, dr.height*dr.th
+	switch dr.layout {
+	case HexRowsLayout:
+		if dr.height > 1 {
+			w += dr.tw / 2
+		}
+	case HexColumnsLayout:
+		if dr.width > 1 {
+			h += dr.th / 2
+		}
+	case IsometricLayout:
+		w = (dr.width + dr.height) * dr.tw / 2
+		h = (dr.width + dr.height) * dr.th / 2
+	}
+	return sdl.Rect{X: int32(dr.padding.X), Y: int32(dr.padding.Y), W: w, H: h}
+}
+
+// drawIsometric draws the whole grid in isometric layout. Tiles are
+// expected to contain a diamond filling the tile. As tiles overlap their
+// neighbors, each frame is drawn entirely, back to front.
+func (dr *Driver) drawIsometric() *FlushError {
+	w, h := int(dr.width), int(dr.height)
+	var ferr *FlushError
+	if err := dr.clear(); err != nil {
+		ferr = addError(ferr, err)
+	}
+	if dr.background != nil {
+		rect := dr.gridRect()
+		err := dr.drawBackground(&rect)
+		if err != nil {
+			dr.logf("background: %v", err)
+		}
+	}
+	for s := 0; s <= w+h-2; s++ {
+		x0 := s - h + 1
+		if x0 < 0 {
+			x0 = 0
+		}
+		for x := x0; x <= s && x < w; x++ {
+			y := s - x
+			if dr.clipped(dr.grid[x+w*y], x, y) {
+				continue
+			}
+			ferr = addError(ferr, dr.draw(dr.grid[x+w*y], x, y))
+		}
+	}
+	return ferr
+}
+
+// layoutCell returns the grid position of the cell containing a point in
+// rendering coordinates.
+func (dr *Driver) layoutCell(x, y float32) gruid.Point {
+	x -= float32(dr.padding.X)
+	y -= float32(dr.padding.Y)
+	tw, th := float32(dr.tw), float32(dr.th)
+	floor := func(v float32) int {
+		return int(math.Floor(float64(v)))
+	}
+	switch dr.layout {
+	case HexRowsLayout:
+		cy := floor(y / th)
+		if cy%2 != 0 {
+			x -= tw / 2
+		}
+		return gruid.Point{X: floor(x / tw), Y: cy}
+	case HexColumnsLayout:
+		cx := floor(x / tw)
+		if cx%2 != 0 {
+			y -= th / 2
+		}
+		return gruid.Point{X: cx, Y: floor(y / th)}
+	case IsometricLayout:
+		// Position relative to the top corner of the diamond of the
+		// cell at (0, 0).
+		u := x - float32(dr.height)*tw/2
+		return gruid.Point{X: floor(u/tw + y/th), Y: floor(y/th - u/tw)}
+	}
+	return gruid.Point{X: floor(x / tw), Y: floor(y / th)}
+}
@@ -0,0 +1,106 @@
+package sdl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/anaseto/gruid"
+)
+
+func TestLayoutGridSize(t *testing.T) {
+	tests := []struct {
+		layout  Layout
+		padding gruid.Point
+		w, h    int32
+	}{
+		{GridLayout, gruid.Point{}, 80, 40},
+		{GridLayout, gruid.Point{X: 3, Y: 2}, 86, 44},
+		{HexRowsLayout, gruid.Point{}, 84, 40},
+		{HexColumnsLayout, gruid.Point{}, 80, 44},
+		{IsometricLayout, gruid.Point{}, 60, 60},
+	}
+	for _, tt := range tests {
+		dr, _ := newTestDriver(t, Config{Layout: tt.layout, Padding: tt.padding})
+		if w, h := dr.gridSize(); w != tt.w || h != tt.h {
+			t.Errorf("layout %d, padding %v: size %dx%d, want %dx%d", tt.layout, tt.padding, w, h, tt.w, tt.h)
+		}
+	}
+}
+
+func TestLayoutPixelToCell(t *testing.T) {
+	tests := []struct {
+		layout Layout
+		p      image.Point
+		want   gruid.Point
+	}{
+		{HexRowsLayout, image.Point{X: 3, Y: 3}, gruid.Point{}},
+		{HexRowsLayout, image.Point{X: 3, Y: 11}, gruid.Point{X: -1, Y: 1}},
+		{HexRowsLayout, image.Point{X: 5, Y: 11}, gruid.Point{X: 0, Y: 1}},
+		{HexRowsLayout, image.Point{X: 12, Y: 11}, gruid.Point{X: 1, Y: 1}},
+		{HexColumnsLayout, image.Point{X: 3, Y: 3}, gruid.Point{}},
+		{HexColumnsLayout, image.Point{X: 11, Y: 3}, gruid.Point{X: 1, Y: -1}},
+		{HexColumnsLayout, image.Point{X: 11, Y: 5}, gruid.Point{X: 1, Y: 0}},
+		{HexColumnsLayout, image.Point{X: 11, Y: 12}, gruid.Point{X: 1, Y: 1}},
+		// The top corner of the cell at (0, 0) is at (20, 0), with
+		// a height of 5 cells.
+		{IsometricLayout, image.Point{X: 20, Y: 1}, gruid.Point{}},
+		{IsometricLayout, image.Point{X: 20, Y: 7}, gruid.Point{}},
+		{IsometricLayout, image.Point{X: 20, Y: 9}, gruid.Point{X: 1, Y: 1}},
+		{IsometricLayout, image.Point{X: 24, Y: 5}, gruid.Point{X: 1, Y: 0}},
+		{IsometricLayout, image.Point{X: 16, Y: 5}, gruid.Point{X: 0, Y: 1}},
+		{IsometricLayout, image.Point{X: 1, Y: 18}, gruid.Point{X: -1, Y: 4}},
+	}
+	for _, tt := range tests {
+		dr, _ := newTestDriver(t, Config{Layout: tt.layout})
+		if got := dr.PixelToCell(tt.p); got != tt.want {
+			t.Errorf("layout %d: PixelToCell(%v) = %v, want %v", tt.layout, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutRoundTrip(t *testing.T) {
+	for _, layout := range []Layout{GridLayout, HexRowsLayout, HexColumnsLayout, IsometricLayout} {
+		dr, _ := newTestDriver(t, Config{Layout: layout, Padding: gruid.Point{X: 5, Y: 3}})
+		for y := 0; y < int(dr.height); y++ {
+			for x := 0; x < int(dr.width); x++ {
+				r := dr.cellRect(x, y)
+				center := image.Point{X: int(r.X + r.W/2), Y: int(r.Y + r.H/2)}
+				if got := dr.PixelToCell(center); got != (gruid.Point{X: x, Y: y}) {
+					t.Errorf("layout %d: center %v of (%d,%d) maps to %v", layout, center, x, y, got)
+				}
+			}
+		}
+	}
+}
+
+func TestLayoutDrawing(t *testing.T) {
+	bg := func(r rune) color.RGBA { return testColor(r, 0) }
+	black := color.RGBA{A: 0xff}
+	tests := []struct {
+		layout Layout
+		p      image.Point
+		want   color.RGBA
+	}{
+		{GridLayout, image.Point{X: 1, Y: 9}, bg('c')},
+		{HexRowsLayout, image.Point{X: 1, Y: 1}, bg('a')},
+		{HexRowsLayout, image.Point{X: 1, Y: 9}, black},
+		{HexRowsLayout, image.Point{X: 5, Y: 9}, bg('c')},
+		{HexColumnsLayout, image.Point{X: 9, Y: 1}, black},
+		{HexColumnsLayout, image.Point{X: 9, Y: 5}, bg('b')},
+		{IsometricLayout, image.Point{X: 1, Y: 1}, black},
+		{IsometricLayout, image.Point{X: 17, Y: 1}, bg('a')},
+		{IsometricLayout, image.Point{X: 13, Y: 5}, bg('c')},
+		// The cell at (1, 0) is drawn after, and in front of, the cell
+		// at (0, 0).
+		{IsometricLayout, image.Point{X: 21, Y: 5}, bg('b')},
+		{IsometricLayout, image.Point{X: 17, Y: 9}, bg('d')},
+	}
+	for _, tt := range tests {
+		dr, _ := newTestDriver(t, Config{Layout: tt.layout})
+		dr.Flush(testFrame(10, 5, "ab", "cd"))
+		if got := screenshot(t, dr).RGBAAt(tt.p.X, tt.p.Y); got != tt.want {
+			t.Errorf("layout %d: color at %v is %v, want %v", tt.layout, tt.p, got, tt.want)
+		}
+	}
+}
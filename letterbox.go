@@ -0,0 +1,35 @@
+package sdl
+
+import (
+	"math"
+)
+
+// setIntegerScale sets the window size to the given integer multiple of the
+// unscaled window size, for the IntegerScale mode.
+func (dr *Driver) setIntegerScale(scaleX, scaleY float32) {
+	n := int32(math.Round(float64(scaleX)))
+	if scaleY < scaleX {
+		n = int32(math.Round(float64(scaleY)))
+	}
+	if n < 1 {
+		n = 1
+	}
+	dr.intScale = n
+	// Scaling is done by the renderer's logical size, and mouse event
+	// coordinates are already converted by SDL, so the driver's scale
+	// is not used.
+	dr.scaleX, dr.scaleY = 0, 0
+	w, h := dr.windowSize()
+	dr.updateLogicalSize(w, h)
+	dr.window.SetSize(w*n, h*n)
+}
+
+// updateLogicalSize sets the renderer's logical size to the given unscaled
+// window size, so that the content is scaled by the largest integer factor
+// fitting in the window, and centered with letterbox bars.
+func (dr *Driver) updateLogicalSize(w, h int32) {
+	err := dr.renderer.setLogicalSize(w, h, true)
+	if err != nil {
+		dr.logf("logical size: %v", err)
+	}
+}
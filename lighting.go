@@ -0,0 +1,49 @@
+package sdl
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SetOverlay sets a function returning a color blended on top of the cell at
+// each grid position, as for dynamic lighting or fog-of-war shading, without
+// requiring shaded tile variants. The color is not alpha-premultiplied: its
+// alpha is the blending opacity.
+// As the function's results may change at any time, the whole grid is redrawn
+// on each Flush while an overlay function is set. A nil function removes the
+// overlay. If the driver is already running, change will take effect with
+// next Flush so that the function is thread safe.
+func (dr *Driver) SetOverlay(fn func(gruid.Point) color.NRGBA) {
+	set := func() {
+		dr.cellOverlay = fn
+	}
+	if dr.init {
+		dr.queueSet("SetOverlay", set)
+	} else {
+		set()
+	}
+}
+
+// drawCellOverlay blends the overlay color for a cell on top of its tile.
+func (dr *Driver) drawCellOverlay(p gruid.Point, rect *sdl.Rect) error {
+	c := dr.cellOverlay(p)
+	if c.A == 0 {
+		return nil
+	}
+	rd := dr.renderer
+	err := rd.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	if err == nil {
+		err = rd.SetDrawColor(c.R, c.G, c.B, c.A)
+	}
+	if err == nil {
+		err = rd.FillRect(rect)
+	}
+	rd.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+	if err != nil {
+		return fmt.Errorf("draw: overlay: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package sdl
+
+import (
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Magnifier describes a lens showing an enlarged copy of the cells around a
+// given point, helping low-vision players read small tiles without rescaling
+// the whole window.
+type Magnifier struct {
+	// Radius is the number of cells shown around the center in each
+	// direction. The default is 2, for a 5x5 cells lens.
+	Radius int
+
+	// Zoom is the integer magnification factor. The default is 3.
+	Zoom int
+
+	// Center is the magnified cell. If nil, the cell under the mouse is
+	// used.
+	Center *gruid.Point
+
+	// Corner makes the lens be drawn in the top-right corner of the grid,
+	// instead of floating next to the magnified cell.
+	Corner bool
+}
+
+// SetMagnifier shows a magnifier lens drawn on top of the grid, or hides it
+// if nil. If the driver is already running, change will take effect with
+// next Flush so that the function is thread safe.
+func (dr *Driver) SetMagnifier(m *Magnifier) {
+	fn := func() {
+		dr.magnifier = m
+	}
+	if dr.init {
+		dr.queueSet("SetMagnifier", fn)
+	} else {
+		fn()
+	}
+}
+
+// magnifierFollowsMouse reports whether the magnifier has to be redrawn on mouse
+// motion.
+func (dr *Driver) magnifierFollowsMouse() bool {
+	return dr.magnifier != nil && dr.magnifier.Center == nil
+}
+
+// drawMagnifier draws the magnifier lens, using cached tile textures.
+func (dr *Driver) drawMagnifier() {
+	m := dr.magnifier
+	radius, zoom := int32(m.Radius), int32(m.Zoom)
+	if radius <= 0 {
+		radius = 2
+	}
+	if zoom <= 0 {
+		zoom = 3
+	}
+	center := dr.mousepos
+	if m.Center != nil {
+		center = *m.Center
+	}
+	if _, ok := dr.cellAt(center); !ok {
+		return
+	}
+	n := 2*radius + 1
+	tw, th := dr.tw*zoom, dr.th*zoom
+	w, h := n*tw, n*th
+	gw, gh := dr.width*dr.tw, dr.height*dr.th
+	var x0, y0 int32
+	if m.Corner {
+		x0, y0 = gw-w-1, 1
+	} else {
+		x0, y0 = int32(center.X+1)*dr.tw+dr.tw, int32(center.Y+1)*dr.th+dr.th
+		if x0+w+1 > gw {
+			x0 = int32(center.X-1)*dr.tw - w
+		}
+		if y0+h+1 > gh {
+			y0 = int32(center.Y-1)*dr.th - h
+		}
+	}
+	if x0 < 1 {
+		x0 = 1
+	}
+	if y0 < 1 {
+		y0 = 1
+	}
+	r := dr.renderer
+	r.SetDrawColor(255, 255, 255, 255)
+	r.FillRect(&sdl.Rect{X: x0 - 1, Y: y0 - 1, W: w + 2, H: h + 2})
+	r.SetDrawColor(0, 0, 0, 255)
+	r.FillRect(&sdl.Rect{X: x0, Y: y0, W: w, H: h})
+	for j := int32(0); j < n; j++ {
+		for i := int32(0); i < n; i++ {
+			p := center.Add(gruid.Point{X: int(i - radius), Y: int(j - radius)})
+			c, ok := dr.cellAt(p)
+			if !ok {
+				continue
+			}
+			tx, ok := dr.textures[dr.key(c)]
+			if !ok {
+				continue
+			}
+			rect := sdl.Rect{X: x0 + i*tw, Y: y0 + j*th, W: tw, H: th}
+			err := dr.copyTile(c, tx, &rect)
+			if err != nil {
+				dr.logf("magnifier: %v", err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package sdl
+
+import (
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// MsgScreenInfo is reported after each gruid.MsgScreen message when the
+// ScreenInfo configuration option is set. It provides pixel-level
+// information about the screen, useful for applications mixing cell and
+// pixel coordinates.
+type MsgScreenInfo struct {
+	TileSize  gruid.Point // tile size in pixels
+	PixelSize gruid.Point // window size in pixels
+	ScaleX    float32     // horizontal rendering scale
+	ScaleY    float32     // vertical rendering scale
+	DPI       float32     // diagonal DPI of the window's display (zero if unknown)
+	Time      time.Time   // time when the message was generated
+}
+
+// MsgMouseIdle is reported when the mouse has been idle for the duration
+// given by the MouseIdleTimeout configuration option, and then again when the
+// mouse becomes active, along with the first mouse message.
+type MsgMouseIdle struct {
+	Idle bool        // whether the mouse became idle or active again
+	P    gruid.Point // last known mouse position in cells
+	Time time.Time   // time when the message was generated
+}
@@ -0,0 +1,83 @@
+package sdl
+
+import (
+	"time"
+)
+
+// metricsPeriod is the period over which metrics are reported.
+const metricsPeriod = time.Second
+
+// Metrics contains rendering metrics over a period of about a second, as
+// reported to the function set with SetMetricsFunc.
+type Metrics struct {
+	Period       time.Duration // actual duration of the period
+	Frames       int           // number of presented frames
+	FPS          float64       // presented frames per second
+	Cells        int           // number of drawn cells, including redrawn ones
+	Hits         int64         // number of cells drawn using a cached texture
+	Misses       int64         // number of textures created for missing tiles
+	FlushTime    time.Duration // average duration of Flush
+	MaxFlushTime time.Duration // maximum duration of Flush
+}
+
+// SetMetricsFunc sets a function that is called from the main routine about
+// once per second with rendering metrics for the elapsed period, so that
+// applications can show performance information or log regressions. A nil
+// function disables metrics. If the driver is already running, change will
+// take effect with next Flush so that the function is thread safe.
+func (dr *Driver) SetMetricsFunc(fn func(Metrics)) {
+	f := func() {
+		dr.metricsFunc = fn
+		dr.resetMetrics(time.Now())
+	}
+	if dr.init {
+		dr.queueSet("SetMetricsFunc", f)
+	} else {
+		f()
+	}
+}
+
+// resetMetrics starts a new metrics period.
+func (dr *Driver) resetMetrics(now time.Time) {
+	dr.metrics = Metrics{}
+	dr.metricsStart = now
+	dr.metricsHits = dr.cache.Hits
+	dr.metricsMisses = dr.cache.Misses
+}
+
+// addMetricsFrame records a presented frame that took the given Flush
+// duration.
+func (dr *Driver) addMetricsFrame(d time.Duration) {
+	if dr.metricsFunc == nil {
+		return
+	}
+	m := &dr.metrics
+	m.Frames++
+	m.FlushTime += d
+	if d > m.MaxFlushTime {
+		m.MaxFlushTime = d
+	}
+	dr.checkMetrics()
+}
+
+// checkMetrics reports metrics if the current period is over.
+func (dr *Driver) checkMetrics() {
+	if dr.metricsFunc == nil {
+		return
+	}
+	now := time.Now()
+	period := now.Sub(dr.metricsStart)
+	if period < metricsPeriod {
+		return
+	}
+	m := dr.metrics
+	m.Period = period
+	m.FPS = float64(m.Frames) / period.Seconds()
+	if m.Frames > 0 {
+		m.FlushTime /= time.Duration(m.Frames)
+	}
+	m.Hits = dr.cache.Hits - dr.metricsHits
+	m.Misses = dr.cache.Misses - dr.metricsMisses
+	dr.resetMetrics(now)
+	dr.metricsFunc(m)
+}
@@ -0,0 +1,127 @@
+package sdl
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Minimap describes a downscaled rendering of a grid into a rectangle of the
+// window, where each cell is drawn as a small block of uniform color.
+type Minimap struct {
+	// Rect is the minimap's region, in unscaled window pixel
+	// coordinates.
+	Rect image.Rectangle
+
+	// Grid is the grid shown by the minimap. If nil, the main grid is
+	// used. The grid's content is read on each Flush, so it should only
+	// be modified from the main routine.
+	Grid *gruid.Grid
+
+	// Color returns the color used for a cell. If nil, the average color
+	// of the cell's tile is used, so that no additional tileset is
+	// required.
+	Color func(gruid.Cell) color.Color
+}
+
+// SetMinimap shows a minimap drawn on top of the grid on each Flush, or hides
+// it if nil. If the driver is already running, change will take effect with
+// next Flush so that the function is thread safe.
+func (dr *Driver) SetMinimap(mm *Minimap) {
+	fn := func() {
+		dr.minimap = mm
+	}
+	if dr.init {
+		dr.queueSet("SetMinimap", fn)
+	} else {
+		fn()
+	}
+}
+
+// drawMinimap draws the minimap, grouping cells by color.
+func (dr *Driver) drawMinimap() {
+	mm := dr.minimap
+	cellAt := func(p gruid.Point) gruid.Cell {
+		c, _ := dr.cellAt(p)
+		return c
+	}
+	size := gruid.Point{X: int(dr.width), Y: int(dr.height)}
+	if mm.Grid != nil {
+		size = mm.Grid.Size()
+		cellAt = func(p gruid.Point) gruid.Cell {
+			return mm.Grid.At(p.Add(mm.Grid.Bounds().Min))
+		}
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return
+	}
+	r := mm.Rect.Canon()
+	dx, dy := r.Dx(), r.Dy()
+	rects := make(map[color.RGBA][]sdl.Rect)
+	for y := 0; y < size.Y; y++ {
+		y0, y1 := r.Min.Y+y*dy/size.Y, r.Min.Y+(y+1)*dy/size.Y
+		if y1 == y0 {
+			continue
+		}
+		for x := 0; x < size.X; x++ {
+			x0, x1 := r.Min.X+x*dx/size.X, r.Min.X+(x+1)*dx/size.X
+			if x1 == x0 {
+				continue
+			}
+			col := dr.minimapColor(cellAt(gruid.Point{X: x, Y: y}))
+			rects[col] = append(rects[col], sdl.Rect{X: int32(x0), Y: int32(y0), W: int32(x1 - x0), H: int32(y1 - y0)})
+		}
+	}
+	for col, rs := range rects {
+		err := dr.renderer.SetDrawColor(col.R, col.G, col.B, 255)
+		if err == nil {
+			err = dr.renderer.FillRects(rs)
+		}
+		if err != nil {
+			dr.logf("minimap: %v", err)
+			return
+		}
+	}
+}
+
+// minimapColor returns the minimap color for a cell.
+func (dr *Driver) minimapColor(c gruid.Cell) color.RGBA {
+	if dr.minimap.Color != nil {
+		r, g, b, _ := dr.minimap.Color(c).RGBA()
+		return color.RGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: 255}
+	}
+	if col, ok := dr.minimapColors[c]; ok {
+		return col
+	}
+	if dr.minimapColors == nil {
+		dr.minimapColors = make(map[gruid.Cell]color.RGBA)
+	}
+	col := color.RGBA{A: 255}
+	img, err := dr.getImage(c)
+	if err == nil {
+		col = dr.filterColor(averageColor(img))
+	}
+	dr.minimapColors[c] = col
+	return col
+}
+
+// averageColor returns the average color of an image.
+func averageColor(img image.Image) color.RGBA {
+	b := img.Bounds()
+	var r, g, bl, n uint64
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			cr, cg, cb, _ := img.At(x, y).RGBA()
+			r += uint64(cr)
+			g += uint64(cg)
+			bl += uint64(cb)
+			n++
+		}
+	}
+	if n == 0 {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: 255}
+}
@@ -0,0 +1,62 @@
+package sdl
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// nextEvent returns the next pending event, if any, with consecutive mouse
+// motion events coalesced into the latest one.
+func (dr *Driver) nextEvent() sdl.Event {
+	for {
+		event := dr.pendingEvent
+		if event != nil {
+			dr.pendingEvent = nil
+		} else {
+			event = dr.backend.pollEvent()
+		}
+		if event == nil {
+			return nil
+		}
+		event = dr.coalesceMotion(event)
+		if event != nil || dr.pendingEvent == nil {
+			return event
+		}
+	}
+}
+
+// coalesceMotion returns the latest of the queued mouse motion events
+// following the given event, if it is a mouse motion event. Relative motions
+// are accumulated. The first event that is not a mouse motion event is kept
+// for the next call to nextEvent. It returns nil if sub-cell motion is
+// dropped (see Config.DropSubCellMotion) and the mouse stayed in its cell.
+func (dr *Driver) coalesceMotion(event sdl.Event) sdl.Event {
+	motion, ok := event.(*sdl.MouseMotionEvent)
+	if !ok {
+		return event
+	}
+	for {
+		next := dr.backend.pollEvent()
+		ev, ok := next.(*sdl.MouseMotionEvent)
+		if !ok {
+			dr.pendingEvent = next
+			break
+		}
+		ev.XRel += motion.XRel
+		ev.YRel += motion.YRel
+		motion = ev
+	}
+	if dr.dropSubCellMotion && dr.subCellMotion(motion) {
+		return nil
+	}
+	return motion
+}
+
+// subCellMotion reports whether a mouse motion event stays within the cell
+// under the mouse.
+func (dr *Driver) subCellMotion(ev *sdl.MouseMotionEvent) bool {
+	if len(dr.viewports) > 0 {
+		// Viewports have their own cells.
+		return false
+	}
+	return dr.coords(ev.X, ev.Y) == dr.mousepos
+}
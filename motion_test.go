@@ -0,0 +1,51 @@
+package sdl
+
+import (
+	"testing"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+func TestMotionCoalescing(t *testing.T) {
+	dr, hl := newTestDriver(t, Config{})
+	msgs := pollAll(t, dr, hl, 0,
+		&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: 12, Y: 3},
+		&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: 20, Y: 3},
+		&sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: 28, Y: 3},
+		keyDown(sdl.K_UP, 0),
+	)
+	want := []gruid.Msg{
+		gruid.MsgMouse{Action: gruid.MouseMove, P: gruid.Point{X: 3, Y: 0}},
+		gruid.MsgKeyDown{Key: gruid.KeyArrowUp},
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("messages = %v, want %v", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("message %d = %v, want %v", i, msgs[i], want[i])
+		}
+	}
+}
+
+func TestDropSubCellMotion(t *testing.T) {
+	dr, hl := newTestDriver(t, Config{DropSubCellMotion: true})
+	tests := []struct {
+		x    int32
+		want int // number of messages
+	}{
+		{12, 1},
+		{14, 0},
+		{15, 0},
+		{16, 1},
+		{16, 0},
+		{3, 1},
+	}
+	for _, tt := range tests {
+		msgs := pollAll(t, dr, hl, 0, &sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: tt.x, Y: 3})
+		if len(msgs) != tt.want {
+			t.Errorf("motion to x=%d: messages = %v, want %d", tt.x, msgs, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package sdl
+
+import "errors"
+
+// errUnsupported is returned by features that are not available on the
+// current platform.
+var errUnsupported = errors.New("not supported on this platform")
+
+// Notify shows a desktop notification with the given title and body, so that
+// the user can be alerted even if the window is not focused. It uses platform
+// notification facilities: notify-send on Linux and BSDs, osascript on macOS,
+// and PowerShell on Windows. On other platforms, or if the required tool is
+// not available, an error is returned. It is safe to call it from any
+// goroutine.
+func (dr *Driver) Notify(title, body string) error {
+	return notify(title, body)
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !freebsd && !openbsd && !netbsd && !dragonfly && !darwin && !windows
+// +build !linux,!freebsd,!openbsd,!netbsd,!dragonfly,!darwin,!windows
+
+package sdl
+
+func notify(title, body string) error {
+	return errUnsupported
+}
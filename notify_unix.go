@@ -0,0 +1,10 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+// +build linux freebsd openbsd netbsd dragonfly
+
+package sdl
+
+import "os/exec"
+
+func notify(title, body string) error {
+	return exec.Command("notify-send", "--", title, body).Run()
+}
@@ -0,0 +1,29 @@
+package sdl
+
+import (
+	"os"
+	"os/exec"
+)
+
+// notifyScript shows a balloon notification. Title and body are passed
+// through the environment to avoid quoting issues.
+const notifyScript = `Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(5000, $env:GRUID_NOTIFY_TITLE, $env:GRUID_NOTIFY_BODY, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`
+
+func notify(title, body string) error {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", notifyScript)
+	cmd.Env = append(os.Environ(), "GRUID_NOTIFY_TITLE="+title, "GRUID_NOTIFY_BODY="+body)
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+	// The script waits for the notification to be shown before
+	// disposing of it, so we do not wait for it.
+	go cmd.Wait()
+	return nil
+}
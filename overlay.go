@@ -0,0 +1,193 @@
+package sdl
+
+import (
+	"fmt"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SetDebugGrid enables a developer overlay drawing cell boundaries on top of
+// the rendered frame, with coordinate labels every n cells. A non-positive n
+// disables the overlay. If the driver is already running, change will take
+// effect with next Flush so that the function is thread safe.
+func (dr *Driver) SetDebugGrid(n int) {
+	fn := func() {
+		dr.debugGrid = n
+	}
+	if dr.init {
+		dr.queueSet("SetDebugGrid", fn)
+	} else {
+		fn()
+	}
+}
+
+// SetInspector enables or disables the cell inspector developer mode. In
+// that mode, hovering a cell shows a tooltip with its rune, colors, attributes
+// and tile cache status, and clicking a cell logs its full description. It
+// can help TileManager authors debug why a particular cell renders wrongly.
+// If the driver is already running, change will take effect with next Flush
+// so that the function is thread safe.
+func (dr *Driver) SetInspector(enabled bool) {
+	fn := func() {
+		dr.inspector = enabled
+	}
+	if dr.init {
+		dr.queueSet("SetInspector", fn)
+	} else {
+		fn()
+	}
+}
+
+// cellAt returns the current cell at a given position, and whether the
+// position is valid.
+func (dr *Driver) cellAt(p gruid.Point) (gruid.Cell, bool) {
+	w, h := int(dr.width), int(dr.height)
+	if p.X < 0 || p.X >= w || p.Y < 0 || p.Y >= h || len(dr.grid) != w*h {
+		return gruid.Cell{}, false
+	}
+	return dr.grid[p.X+w*p.Y], true
+}
+
+// inspect logs the full description of the cell at the given position.
+func (dr *Driver) inspect(p gruid.Point) {
+	c, ok := dr.cellAt(p)
+	if !ok {
+		return
+	}
+	_, cached := dr.textures[dr.key(c)]
+	dr.logf("inspect %v: %+v (rune %U, cached: %v)", p, c, c.Rune, cached)
+}
+
+// refresh redraws the whole grid along with overlays, and presents it. It is
+// used when overlays change outside of Flush.
+func (dr *Driver) refresh() {
+	dr.needRefresh = false
+	if !dr.init || len(dr.grid) == 0 || dr.suspended {
+		return
+	}
+	if _, ok := dr.renderer.(lostRenderer); ok {
+		return
+	}
+	full := dr.fullRedraw
+	dr.beginDraw()
+	err := dr.drawFrame(gruid.Frame{Width: int(dr.width), Height: int(dr.height)})
+	if err != nil {
+		dr.handleError(err)
+	}
+	if full {
+		dr.drawn = true
+	}
+	dr.present()
+}
+
+// hasOverlays reports whether some overlay has to be drawn on top of the
+// grid.
+func (dr *Driver) hasOverlays() bool {
+	return dr.debugGrid > 0 || dr.inspector || dr.minimap != nil || !dr.selection.Empty() ||
+		dr.hoverColor != nil || dr.showVirtualKeyboard() || dr.magnifier != nil ||
+		dr.dragGhost != nil || dr.postRender != nil || dr.crt != nil ||
+		dr.adjusted() || len(dr.sprites) > 0 || dr.cellOverlay != nil || dr.fps != nil
+}
+
+// drawOverlays draws active overlays on top of the grid.
+func (dr *Driver) drawOverlays() {
+	if len(dr.sprites) > 0 {
+		dr.drawSprites()
+	}
+	if !dr.selection.Empty() {
+		dr.drawSelection()
+	}
+	if dr.hoverColor != nil {
+		dr.drawHover()
+	}
+	if dr.minimap != nil {
+		dr.drawMinimap()
+	}
+	if dr.magnifier != nil {
+		dr.drawMagnifier()
+	}
+	if dr.debugGrid > 0 {
+		dr.drawDebugGrid()
+	}
+	if dr.inspector {
+		dr.drawInspector()
+	}
+	if dr.fps != nil {
+		dr.drawFPS()
+	}
+	if dr.dragGhost != nil {
+		dr.drawDragGhost()
+	}
+	if dr.showVirtualKeyboard() {
+		dr.drawVirtualKeyboard()
+	}
+	if dr.adjusted() {
+		dr.drawAdjustments()
+	}
+	if dr.crt != nil {
+		dr.drawCRT()
+	}
+	if dr.postRender != nil {
+		dr.drawPostRender()
+	}
+}
+
+func (dr *Driver) drawDebugGrid() {
+	r := dr.renderer
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	defer r.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+	r.SetDrawColor(255, 255, 0, 96)
+	w, h := dr.width*dr.tw, dr.height*dr.th
+	rects := make([]sdl.Rect, 0, dr.width+dr.height)
+	for x := int32(1); x < dr.width; x++ {
+		rects = append(rects, sdl.Rect{X: x * dr.tw, Y: 0, W: 1, H: h})
+	}
+	for y := int32(1); y < dr.height; y++ {
+		rects = append(rects, sdl.Rect{X: 0, Y: y * dr.th, W: w, H: 1})
+	}
+	err := r.FillRects(rects)
+	if err != nil {
+		dr.logf("debug grid: %v", err)
+	}
+	n := int32(dr.debugGrid)
+	for y := int32(0); y < dr.height; y += n {
+		for x := int32(0); x < dr.width; x += n {
+			dr.drawLabel(x*dr.tw+1, y*dr.th+1, fmt.Sprintf("%d,%d", x, y))
+		}
+	}
+}
+
+func (dr *Driver) drawInspector() {
+	p := dr.mousepos
+	c, ok := dr.cellAt(p)
+	if !ok {
+		return
+	}
+	_, cached := dr.textures[dr.key(c)]
+	rs := fmt.Sprintf("%U", c.Rune)
+	if c.Rune > ' ' && c.Rune < 127 {
+		rs += fmt.Sprintf(" '%c'", c.Rune)
+	}
+	lines := []string{
+		fmt.Sprintf("%d,%d %s", p.X, p.Y, rs),
+		fmt.Sprintf("FG %d BG %d", c.Style.Fg, c.Style.Bg),
+		fmt.Sprintf("ATTRS %#x", c.Style.Attrs),
+		fmt.Sprintf("CACHED %v", cached),
+	}
+	w, h := labelSize(lines...)
+	x, y := int32(p.X+1)*dr.tw, int32(p.Y+1)*dr.th
+	if x+w > dr.width*dr.tw {
+		x = int32(p.X)*dr.tw - w
+	}
+	if y+h > dr.height*dr.th {
+		y = int32(p.Y)*dr.th - h
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	dr.drawLabel(x, y, lines...)
+}
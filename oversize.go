@@ -0,0 +1,61 @@
+package sdl
+
+import (
+	"image"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// setTileSize records the size of a tile's image, if larger than the tile
+// size. Such oversized tiles, like tall creatures, are drawn anchored to the
+// bottom-left corner of their cell, overlapping neighbor cells.
+func (dr *Driver) setTileSize(key gruid.Cell, size image.Point) {
+	if size.X <= int(dr.tw) && size.Y <= int(dr.th) {
+		delete(dr.oversized, key)
+		return
+	}
+	if dr.oversized == nil {
+		dr.oversized = make(map[gruid.Cell]image.Point)
+	}
+	dr.oversized[key] = size
+}
+
+// tileRect returns the rectangle into which a cell's tile is drawn, given
+// the cell's rectangle.
+func (dr *Driver) tileRect(key gruid.Cell, rect sdl.Rect) sdl.Rect {
+	size, ok := dr.oversized[key]
+	if !ok {
+		return rect
+	}
+	rect.Y += rect.H - int32(size.Y)
+	rect.W = int32(size.X)
+	rect.H = int32(size.Y)
+	return rect
+}
+
+// drawGrid draws all the cells of the grid. If there are oversized tiles,
+// they are drawn after the others, row by row, so that they overlap their
+// neighbors, and tiles of lower rows are in front.
+func (dr *Driver) drawGrid() *FlushError {
+	w := int(dr.width)
+	var ferr *FlushError
+	deferred := dr.deferred[:0]
+	for i, c := range dr.grid {
+		if dr.clipped(c, i%w, i/w) {
+			continue
+		}
+		if len(dr.oversized) > 0 {
+			if _, ok := dr.oversized[dr.key(c)]; ok {
+				deferred = append(deferred, i)
+				continue
+			}
+		}
+		ferr = addError(ferr, dr.draw(c, i%w, i/w))
+	}
+	for _, i := range deferred {
+		ferr = addError(ferr, dr.draw(dr.grid[i], i%w, i/w))
+	}
+	dr.deferred = deferred
+	return ferr
+}
@@ -0,0 +1,72 @@
+package sdl
+
+import (
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// MsgPacingStats is reported periodically when the PacingInterval
+// configuration option is set. It provides presentation statistics for the
+// elapsed interval, so that adaptive applications can lower effect quality
+// when the machine cannot keep up.
+type MsgPacingStats struct {
+	Presented  int           // number of presented frames
+	Coalesced  int           // number of frames merged into others before presentation
+	Stalls     int           // number of frames exceeding the frame budget
+	AvgLatency time.Duration // average delay between frame creation and presentation
+	MaxLatency time.Duration // maximum delay between frame creation and presentation
+	Interval   time.Duration // duration covered by the statistics
+	Time       time.Time     // time when the message was generated
+}
+
+// pacing accumulates presentation statistics between two MsgPacingStats
+// messages.
+type pacing struct {
+	start   time.Time
+	msg     MsgPacingStats
+	latency time.Duration // total latency
+	samples int           // frames with known creation time
+}
+
+// addPresented records a presented frame, and queues a MsgPacingStats
+// message if the interval elapsed.
+func (dr *Driver) addPresented(frame gruid.Frame, end time.Time, stall bool) {
+	if dr.pacingInterval <= 0 {
+		return
+	}
+	pc := &dr.pacing
+	if pc.start.IsZero() {
+		pc.start = end
+	}
+	pc.msg.Presented++
+	if stall {
+		pc.msg.Stalls++
+	}
+	if !frame.Time.IsZero() && !end.Before(frame.Time) {
+		lat := end.Sub(frame.Time)
+		pc.latency += lat
+		pc.samples++
+		if lat > pc.msg.MaxLatency {
+			pc.msg.MaxLatency = lat
+		}
+	}
+	if end.Sub(pc.start) < dr.pacingInterval {
+		return
+	}
+	msg := pc.msg
+	if pc.samples > 0 {
+		msg.AvgLatency = pc.latency / time.Duration(pc.samples)
+	}
+	msg.Interval = end.Sub(pc.start)
+	msg.Time = end
+	dr.msgs = append(dr.msgs, msg)
+	*pc = pacing{start: end}
+}
+
+// addCoalesced records a frame merged into another before presentation.
+func (dr *Driver) addCoalesced() {
+	if dr.pacingInterval > 0 {
+		dr.pacing.msg.Coalesced++
+	}
+}
@@ -0,0 +1,72 @@
+package sdl
+
+import (
+	"testing"
+	"time"
+)
+
+// pacingStats returns the MsgPacingStats messages reported by the driver.
+func pacingStats(t *testing.T, dr *Driver) []MsgPacingStats {
+	t.Helper()
+	var stats []MsgPacingStats
+	for {
+		msg, err := dr.PollMsg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg == nil {
+			return stats
+		}
+		if st, ok := msg.(MsgPacingStats); ok {
+			stats = append(stats, st)
+		}
+	}
+}
+
+func TestPacingStats(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{PacingInterval: time.Nanosecond})
+	frame := testFrame(10, 5, "a")
+	frame.Time = time.Now().Add(-10 * time.Millisecond)
+	dr.Flush(frame)
+	time.Sleep(time.Millisecond)
+	dr.Flush(testFrame(10, 5, "b"))
+	// Unchanged frames are not presented.
+	dr.Flush(testFrame(10, 5, "b"))
+	stats := pacingStats(t, dr)
+	if len(stats) != 1 {
+		t.Fatalf("%d messages, want 1", len(stats))
+	}
+	st := stats[0]
+	if st.Presented != 2 || st.Coalesced != 0 {
+		t.Errorf("presented %d and coalesced %d frames, want 2 and 0", st.Presented, st.Coalesced)
+	}
+	if st.AvgLatency < 10*time.Millisecond || st.MaxLatency != st.AvgLatency {
+		t.Errorf("average latency %v and maximum %v, want same, at least 10ms", st.AvgLatency, st.MaxLatency)
+	}
+	if st.Interval <= 0 || st.Time.IsZero() {
+		t.Errorf("bad interval %v or time %v", st.Interval, st.Time)
+	}
+}
+
+func TestPacingCoalesced(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{PacingInterval: time.Nanosecond})
+	dr.SetFramePause(true)
+	n := maxPausedFrames + 2
+	for i := 0; i < n; i++ {
+		dr.Flush(testFrame(10, 5, string(rune('a'+i%2))))
+	}
+	if len(dr.pausedFrames) != maxPausedFrames {
+		t.Fatalf("%d paused frames, want %d", len(dr.pausedFrames), maxPausedFrames)
+	}
+	dr.SetFramePause(false)
+	dr.Flush(testFrame(10, 5, "c"))
+	presented, coalesced := 0, 0
+	for _, st := range pacingStats(t, dr) {
+		presented += st.Presented
+		coalesced += st.Coalesced
+	}
+	// All queued frames and the last one are presented.
+	if presented != maxPausedFrames+1 || coalesced != 2 {
+		t.Errorf("presented %d and coalesced %d frames, want %d and 2", presented, coalesced, maxPausedFrames+1)
+	}
+}
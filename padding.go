@@ -0,0 +1,43 @@
+package sdl
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// clear fills the current rendering target with the background color (see
+// Config.BackgroundColor), or only the clipping rectangle, if any.
+func (dr *Driver) clear() error {
+	r, g, b := rgb(dr.bgColor)
+	err := dr.renderer.SetDrawColor(r, g, b, 255)
+	if err != nil {
+		return err
+	}
+	if dr.clip != nil {
+		return dr.renderer.FillRect(dr.clip)
+	}
+	return dr.renderer.Clear()
+}
+
+// drawPadding fills the margins around the grid with the background color,
+// if there is padding.
+func (dr *Driver) drawPadding() {
+	if dr.padding.X <= 0 && dr.padding.Y <= 0 {
+		return
+	}
+	gr := dr.gridRect()
+	w, h := dr.windowSize()
+	rects := []sdl.Rect{
+		{W: w, H: gr.Y},
+		{Y: gr.Y + gr.H, W: w, H: h - gr.Y - gr.H},
+		{Y: gr.Y, W: gr.X, H: gr.H},
+		{X: gr.X + gr.W, Y: gr.Y, W: w - gr.X - gr.W, H: gr.H},
+	}
+	r, g, b := rgb(dr.bgColor)
+	err := dr.renderer.SetDrawColor(r, g, b, 255)
+	if err == nil {
+		err = dr.renderer.FillRects(rects)
+	}
+	if err != nil {
+		dr.logf("padding: %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package sdl
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/anaseto/gruid"
+)
+
+// clearTileManager returns fully transparent tiles.
+type clearTileManager struct{}
+
+func (tm clearTileManager) GetImage(c gruid.Cell) image.Image {
+	return image.NewNRGBA(image.Rect(0, 0, testTileSize, testTileSize))
+}
+
+func (tm clearTileManager) TileSize() gruid.Point {
+	return gruid.Point{X: testTileSize, Y: testTileSize}
+}
+
+func TestBackgroundColor(t *testing.T) {
+	bg := color.RGBA{R: 0x20, G: 0x40, B: 0x60, A: 0xff}
+	dr, _ := newTestDriver(t, Config{
+		TileManager:     clearTileManager{},
+		Padding:         gruid.Point{X: 4, Y: 4},
+		BackgroundColor: bg,
+		GhostAttr:       1,
+	})
+	frame := testFrame(10, 5, "ab", "cd")
+	for i := range frame.Cells {
+		// Ghost cells are cleared before drawing.
+		frame.Cells[i].Cell.Style.Attrs = 1
+	}
+	dr.Flush(frame)
+	img := screenshot(t, dr)
+	for _, p := range []image.Point{{X: 1, Y: 1}, {X: 5, Y: 5}, {X: 13, Y: 13}, {X: 83, Y: 43}} {
+		if got := img.RGBAAt(p.X, p.Y); got != bg {
+			t.Errorf("color at %v is %v, want %v", p, got, bg)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package sdl
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SetPostRenderFunc registers a callback executed after the grid and
+// overlays are drawn, but before the frame is presented, so that custom
+// pixel-level graphics can be drawn on top of the grid. The callback
+// receives the SDL renderer, along with the width and height of the
+// rendering area, in rendering coordinates: the renderer's scale and target
+// should be left unchanged. As for other overlays, the whole grid is redrawn
+// on each Flush while a callback is registered. The callback is not called in
+// headless mode. A nil function removes the callback. If the driver is
+// already running, change will take effect with next Flush so that the
+// function is thread safe.
+func (dr *Driver) SetPostRenderFunc(fn func(r *sdl.Renderer, width, height int32)) {
+	set := func() {
+		dr.postRender = fn
+	}
+	if dr.init {
+		dr.queueSet("SetPostRenderFunc", set)
+	} else {
+		set()
+	}
+}
+
+// drawPostRender calls the post-render callback, if any.
+func (dr *Driver) drawPostRender() {
+	r, ok := dr.renderer.(*sdlRenderer)
+	if !ok {
+		return
+	}
+	w, h := dr.windowSize()
+	dr.postRender(r.Renderer, w, h)
+	// Restore state that the callback might have changed.
+	r.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+}
@@ -0,0 +1,71 @@
+package sdl
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// ScaleQuality represents the filtering used when scaling textures.
+type ScaleQuality int
+
+// Scale qualities.
+const (
+	ScaleDefault ScaleQuality = iota // SDL's default, nearest unless overridden by the environment
+	ScaleNearest                     // nearest pixel sampling, crisp for pixel-art tiles
+	ScaleLinear                      // linear filtering, smooth for fonts
+	ScaleBest                        // anisotropic filtering, if supported, linear otherwise
+)
+
+// hint returns the SDL_HINT_RENDER_SCALE_QUALITY value for the quality.
+func (q ScaleQuality) hint() string {
+	switch q {
+	case ScaleNearest:
+		return "nearest"
+	case ScaleLinear:
+		return "linear"
+	case ScaleBest:
+		return "best"
+	default:
+		return ""
+	}
+}
+
+// SetScaleQuality changes the filtering used when scaling tiles (see
+// Config.ScaleQuality). As it only applies to new textures, the texture
+// cache is cleared, and a redraw requested. If the driver is already
+// running, change will take effect with next Flush so that the function is
+// thread safe.
+func (dr *Driver) SetScaleQuality(q ScaleQuality) {
+	fn := func() {
+		dr.scaleQuality = q
+		if !dr.init {
+			return
+		}
+		dr.setScaleQuality()
+		dr.ClearCache()
+		select {
+		case dr.reqredraw <- true:
+		default:
+		}
+	}
+	if dr.init {
+		dr.queueSet("SetScaleQuality", fn)
+	} else {
+		fn()
+	}
+}
+
+// setScaleQuality sets the scale quality hint used for texture creation.
+func (dr *Driver) setScaleQuality() {
+	h := dr.scaleQuality.hint()
+	if h == "" {
+		// Restore SDL's default, in case another quality was set
+		// before. This fails if the hint is set by the environment,
+		// which then takes precedence anyway.
+		dr.backend.setHint(sdl.HINT_RENDER_SCALE_QUALITY, "nearest")
+		return
+	}
+	err := dr.backend.setHint(sdl.HINT_RENDER_SCALE_QUALITY, h)
+	if err != nil {
+		dr.logf("scale quality: %v", err)
+	}
+}
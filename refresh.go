@@ -0,0 +1,64 @@
+package sdl
+
+import (
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// defaultRefreshRate is the refresh rate assumed when it is unknown.
+const defaultRefreshRate = 60
+
+// MsgRefreshRate is reported when the refresh rate of the display hosting
+// the window changes, for example after the window moved to another display.
+type MsgRefreshRate struct {
+	Hz   int       // new refresh rate in Hz
+	Time time.Time // time when the message was generated
+}
+
+// RefreshRate returns the refresh rate in Hz of the display currently hosting
+// the window, or zero if unknown. It is updated when the window moves to
+// another display. It should be called from the main routine.
+func (dr *Driver) RefreshRate() int {
+	return dr.refreshRate
+}
+
+// FrameInterval returns the duration of a frame for the display currently
+// hosting the window, assuming 60 Hz if the refresh rate is unknown. It can
+// be used for matching animation timing with 60, 120 or 144 Hz displays. It
+// should be called from the main routine.
+func (dr *Driver) FrameInterval() time.Duration {
+	hz := dr.refreshRate
+	if hz <= 0 {
+		hz = defaultRefreshRate
+	}
+	return time.Second / time.Duration(hz)
+}
+
+// Frames returns the number of frames of the display currently hosting the
+// window that fit in the given duration, rounded to the nearest integer, but
+// at least one for positive durations. It should be called from the main
+// routine.
+func (dr *Driver) Frames(d time.Duration) int {
+	fi := dr.FrameInterval()
+	n := int((d + fi/2) / fi)
+	if n == 0 && d > 0 {
+		n = 1
+	}
+	return n
+}
+
+// updateRefreshRate queries the display's refresh rate, and returns a
+// MsgRefreshRate if it changed.
+func (dr *Driver) updateRefreshRate() gruid.Msg {
+	hz, err := dr.backend.refreshRate(dr.window)
+	if err != nil {
+		dr.logf("refresh rate: %v", err)
+		return nil
+	}
+	if hz == dr.refreshRate {
+		return nil
+	}
+	dr.refreshRate = hz
+	return MsgRefreshRate{Hz: hz, Time: time.Now()}
+}
@@ -0,0 +1,42 @@
+package sdl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// RenderDrivers returns the names of the SDL render drivers available in the
+// current environment, in SDL's order of preference. They can be used for
+// Config.RenderDriver.
+func RenderDrivers() ([]string, error) {
+	n, err := sdl.GetNumRenderDrivers()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		var info sdl.RendererInfo
+		if _, err := sdl.GetRenderDriverInfo(i, &info); err != nil {
+			return nil, err
+		}
+		names = append(names, info.Name)
+	}
+	return names, nil
+}
+
+// renderDriverIndex returns the index of the SDL render driver with the given
+// name.
+func renderDriverIndex(name string) (int, error) {
+	names, err := RenderDrivers()
+	if err != nil {
+		return -1, err
+	}
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown render driver %q (available: %s)", name, strings.Join(names, ", "))
+}
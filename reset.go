@@ -0,0 +1,35 @@
+package sdl
+
+import (
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// pollRenderEvent handles renderer reset events. When the device is lost,
+// as may happen after a GPU driver reset, all textures are invalid, so caches
+// are emptied and rebuilt as tiles are drawn again. In both cases, a redraw
+// of the whole screen is requested, because the window content is lost.
+func (dr *Driver) pollRenderEvent(ev *sdl.RenderEvent) {
+	switch ev.Type {
+	case sdl.RENDER_DEVICE_RESET:
+		dr.logf("renderer device reset: rebuilding textures")
+		dr.ClearCache()
+		for _, vp := range dr.viewports {
+			vp.ClearCache()
+		}
+		dr.clearDragGhost()
+		dr.destroyBackbuffer()
+	case sdl.RENDER_TARGETS_RESET:
+		dr.logf("renderer targets reset")
+	default:
+		return
+	}
+	// Target textures content is lost.
+	dr.endTransition()
+	dr.drawn = false
+	dr.invalidateViewports()
+	dr.clear()
+	select {
+	case dr.reqredraw <- true:
+	default:
+	}
+}
@@ -0,0 +1,54 @@
+package sdl
+
+import (
+	"github.com/anaseto/gruid"
+)
+
+// scaledTileSize returns the size of a tile in window pixels.
+func (dr *Driver) scaledTileSize() (int32, int32) {
+	if dr.scaleX > 0.1 && dr.scaleY > 0.1 {
+		return int32(float32(dr.tw) * dr.scaleX), int32(float32(dr.th) * dr.scaleY)
+	}
+	return dr.tw, dr.th
+}
+
+// windowResized handles a window size change, snapping the window size to a
+// multiple of the tile size if configured. It returns a gruid.MsgScreen
+// message, so that the application can adapt its grid to the new size.
+func (dr *Driver) windowResized() gruid.Msg {
+	if dr.integerScale {
+		// The grid is scaled to fit the new size, so the
+		// application does not need to redraw it.
+		dr.clear()
+		dr.invalidateViewports()
+		dr.fullRedraw = true
+		dr.needRefresh = true
+		return nil
+	}
+	if !dr.resizable {
+		return nil
+	}
+	if dr.snapResize {
+		w, h := dr.window.GetSize()
+		tw, th := dr.scaledTileSize()
+		sw, sh := w-w%tw, h-h%th
+		if sw < tw {
+			sw = tw
+		}
+		if sh < th {
+			sh = th
+		}
+		if sw != w || sh != h {
+			// A new size change event will follow.
+			dr.window.SetSize(sw, sh)
+			return nil
+		}
+	}
+	// The window's content is undefined outside the previous grid.
+	err := dr.clear()
+	if err != nil {
+		dr.logf("renderer clear: %v", err)
+	}
+	dr.needRefresh = true
+	return dr.screenMsg()
+}
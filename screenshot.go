@@ -0,0 +1,117 @@
+package sdl
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+// MsgScreenshot is reported after a screenshot has been taken with the
+// screenshot key (see Config.ScreenshotDir).
+type MsgScreenshot struct {
+	Path string    // path of the saved screenshot
+	Err  error     // error, if the screenshot could not be saved
+	Time time.Time // time when the screenshot was taken
+}
+
+// Screenshot returns an image with the current window content. It should be
+// called from the main routine, like Flush.
+func (dr *Driver) Screenshot() (image.Image, error) {
+	if !dr.init {
+		return nil, fmt.Errorf("screenshot: driver not initialized")
+	}
+	img, err := dr.renderer.readPixels()
+	if err != nil {
+		return nil, fmt.Errorf("screenshot: %v", err)
+	}
+	return img, nil
+}
+
+// SaveScreenshot saves current window content as a PNG file with a
+// timestamped name in the given directory, which is created if necessary.
+// It returns the path of the saved file.
+func (dr *Driver) SaveScreenshot(dir string) (string, error) {
+	img, err := dr.Screenshot()
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+	name := "screenshot-" + time.Now().Format("20060102-150405.000") + ".png"
+	path := filepath.Join(dir, name)
+	err = writePNG(path, img)
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// composeGrid returns an image of the current grid content, composed
+// directly from the TileManager's images. Unlike Screenshot, it does not
+// depend on the renderer, so it can be used even after Close.
+func (dr *Driver) composeGrid() *image.RGBA {
+	w, h := int(dr.width), int(dr.height)
+	tw, th := int(dr.tw), int(dr.th)
+	img := image.NewRGBA(image.Rect(0, 0, w*tw, h*th))
+	draw.Draw(img, img.Rect, image.Black, image.Point{}, draw.Src)
+	if len(dr.grid) != w*h || dr.tm == nil {
+		return img
+	}
+	cache := make(map[gruid.Cell]image.Image)
+	for i, c := range dr.grid {
+		tile, ok := cache[c]
+		if !ok {
+			tile = dr.tileImage(c)
+			cache[c] = tile
+		}
+		if tile == nil {
+			continue
+		}
+		x, y := i%w, i/w
+		r := image.Rect(x*tw, y*th, (x+1)*tw, (y+1)*th)
+		draw.Draw(img, r, tile, tile.Bounds().Min, draw.Over)
+	}
+	return img
+}
+
+// tileImage returns the TileManager's image for a cell, recovering from
+// panics.
+func (dr *Driver) tileImage(c gruid.Cell) (img image.Image) {
+	defer func() {
+		if r := recover(); r != nil {
+			img = nil
+		}
+	}()
+	img, _ = dr.getImage(c)
+	return img
+}
+
+// writePNG writes an image as a PNG file.
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(f, img)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// screenshotMsg saves a screenshot in the configured directory and returns a
+// MsgScreenshot.
+func (dr *Driver) screenshotMsg() MsgScreenshot {
+	msg := MsgScreenshot{Time: time.Now()}
+	msg.Path, msg.Err = dr.SaveScreenshot(dr.screenshotDir)
+	return msg
+}
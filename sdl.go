@@ -2,17 +2,17 @@
 package sdl
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"image"
+	"image/color"
+	"image/draw"
 	"log"
+	"sync"
 	"time"
 	"unicode/utf8"
 
-	"golang.org/x/image/bmp"
-
 	"github.com/anaseto/gruid"
 	"github.com/veandco/go-sdl2/sdl"
 )
@@ -20,6 +20,9 @@ import (
 // TileManager manages tiles fetching.
 type TileManager interface {
 	// GetImage returns the image to be used for a given cell style.
+	// Images larger than the tile size, such as for tall creatures, are
+	// drawn anchored to the bottom-left corner of the cell, on top of
+	// neighbor cells.
 	GetImage(gruid.Cell) image.Image
 
 	// TileSize returns the (width, height) in pixels of the tiles. Both
@@ -27,6 +30,37 @@ type TileManager interface {
 	TileSize() gruid.Point
 }
 
+// TileManagerErr is an optional extension of TileManager for tile managers
+// that can report why a tile could not be provided, such as a missing glyph
+// or a font loading error. When implemented, the driver uses GetImageErr
+// instead of GetImage, and reports errors as *TileError values to the error
+// handler (see Config.ErrorHandler).
+type TileManagerErr interface {
+	TileManager
+
+	// GetImageErr returns the image to be used for a given cell style, or
+	// an error if no image can be provided.
+	GetImageErr(gruid.Cell) (image.Image, error)
+}
+
+// TileError is returned when no tile could be obtained for a cell.
+type TileError struct {
+	Cell gruid.Cell // cell for which the tile was requested
+	Err  error      // error reported by the TileManager, if any
+}
+
+func (e *TileError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("no tile for %+v", e.Cell)
+	}
+	return fmt.Sprintf("no tile for %+v: %v", e.Cell, e.Err)
+}
+
+// Unwrap returns the error reported by the TileManager.
+func (e *TileError) Unwrap() error {
+	return e.Err
+}
+
 // Driver implements gruid.Driver using the go-sdl2 bindings for the SDL
 // library. When using an gruid.App, Start has to be used on the main routine,
 // as the video functions of SDL are not thread safe.
@@ -38,20 +72,224 @@ type Driver struct {
 	tw         int32
 	th         int32
 
-	window      *sdl.Window
-	renderer    *sdl.Renderer
-	textures    map[gruid.Cell]*sdl.Texture
+	backend     backend
+	window      window
+	renderer    renderer
+	textures    map[gruid.Cell]texture
 	mousepos    gruid.Point
 	mousedrag   gruid.MouseAction
 	init        bool
 	reqredraw   chan bool // request redraw
 	noQuit      bool      // do not quit on close
-	actions     chan func()
+	actionsMu   sync.Mutex
+	actions     []action // actions queued for next Flush
 	accelerated bool
 	scaleX      float32
 	scaleY      float32
 	title       string
+	percent     float64 // fraction shown in title (if non-negative)
+	opacity     float32
 	icon        image.Image
+	recover     bool
+	onError     func(error)
+	logger      *log.Logger
+	traceInput  bool
+	logs        history // recent log messages
+	inputs      history // recent input messages
+	stats       stats
+	msgs        []gruid.Msg // queued messages
+	screenInfo  bool
+	hooks       FrameHooks
+
+	screenshotDir string
+	screenshotKey sdl.Keycode
+
+	grid      []gruid.Cell // current screen content
+	overlaid  bool         // whether overlays were drawn in last frame
+	debugGrid int          // debug grid label interval
+
+	needRefresh bool // overlays changed outside of Flush
+	inspector   bool // cell inspector mode
+
+	debugKeys    bool
+	paused       bool          // frame pausing
+	pausedFrames []gruid.Frame // frames waiting for presentation
+	slowMotion   float64       // slow motion factor
+	lastPresent  time.Time
+
+	timelapseCfg Timelapse
+	timelapse    *timelapse
+	stream       *stream
+	cast         *cast
+	frameBudget  time.Duration
+	wideRunes    bool
+	cursor       *Cursor
+	misses       []gruid.Cell // cache misses buffer
+	dirty        []bool       // cells to be redrawn
+	lastStallLog time.Time
+
+	cacheKey   func(gruid.Cell) gruid.Cell
+	ghostAttr  gruid.AttrMask
+	ghostAlpha uint8
+
+	pixelToCell func(image.Point) gruid.Point
+
+	viewports  []*Viewport
+	vpDrag     *Viewport   // viewport where a mouse drag started
+	mousePixel image.Point // last mouse position in window pixels
+
+	minimap       *Minimap
+	minimapColors map[gruid.Cell]color.RGBA // cached average tile colors
+
+	hideCursor   bool
+	cursorIdle   time.Duration
+	cursorHidden bool
+	lastMotion   time.Time // last mouse activity
+
+	fullscreenKeys bool
+	selection      gruid.Range
+	selectionColor color.Color
+	hoverColor     color.Color
+
+	mouseIdle     time.Duration
+	mouseIdleSent bool
+	lastMouse     time.Time
+
+	virtualKeyboard bool
+	vkbd            virtualKeyboard
+
+	magnifier *Magnifier
+	position  *image.Point       // initial window position
+	size      *image.Point       // initial window size, for resizable windows
+	volumes   map[string]float64 // audio volumes kept for applications
+
+	pacingInterval time.Duration
+	pacing         pacing
+
+	tickRate float64
+	ticker   *ticker
+
+	flushDeadline time.Duration
+	onFlushHang   func(FlushHang)
+	tileWorkers   int
+
+	compactTextures bool
+	dragGhost       *dragGhost
+	eventHandler    func(sdl.Event) gruid.Msg
+	refreshRate     int // refresh rate of the window's display
+
+	resizable  bool
+	snapResize bool
+	splash     image.Image
+	splashed   bool // whether the window shows the splash image
+
+	errorInterval  time.Duration
+	errorThreshold int
+	failures       failures
+
+	vsync bool
+
+	drawn     bool              // whether the window shows the grid's content
+	diffCells []gruid.FrameCell // buffer for changed frame cells
+
+	backbuffer bool
+	bb         texture // offscreen rendering target
+	bbSize     image.Point
+
+	glyphColors func(gruid.Style) (fg, bg color.Color)
+
+	atlas      bool
+	atlasPages []*atlasPage
+	atlasMax   int32 // atlas page size, or zero if not yet known
+
+	streaming   bool
+	streamPool  map[image.Point][]texture // released streaming textures
+	streamSizes map[texture]image.Point   // sizes of streaming textures
+
+	cacheLimit       int
+	cacheMemoryLimit int
+	lru              cacheLRU
+	cache            CacheStats // cache counters, published after each Flush
+
+	maxFPS      int
+	nextPresent time.Time // earliest time for next frame, if MaxFPS is set
+
+	suspended  bool // whether rendering is suspended while the window is hidden
+	fullRedraw bool // whether next drawn frame should redraw the whole grid
+
+	integerScale bool
+	intScale     int32 // initial integer scale of the window
+	scaleQuality ScaleQuality
+
+	zoom      zoom
+	zoomWheel bool
+
+	shake      shake
+	transition transition
+	faded      bool      // whether content is faded out to black
+	presented  time.Time // time of last present
+
+	postRender func(*sdl.Renderer, int32, int32)
+
+	crt        *CRTFilter
+	crtOverlay crtOverlay
+
+	colorFilter ColorFilter
+
+	tint       color.RGBA
+	brightness float32
+
+	animations  map[gruid.Cell]*animation
+	animFrames  map[int]int // current frame of animated cells by grid index
+	animChanged []int       // buffer for cells with a changed frame
+	animStart   time.Time
+	animChecked time.Time
+
+	reverseAttr    gruid.AttrMask
+	underlineAttr  gruid.AttrMask
+	blinkAttr      gruid.AttrMask
+	underlineColor func(gruid.Style) color.Color
+	blinkInterval  time.Duration
+	blinking       map[int]bool // blinking cells by grid index
+	blinkStart     time.Time
+	blinkOff       bool // whether blinking cells are currently blank
+
+	sprites        []Sprite
+	spriteTextures map[image.Image]texture
+
+	background image.Image
+	bgTexture  texture
+
+	cellOverlay func(gruid.Point) color.NRGBA
+
+	layout Layout
+
+	oversized map[gruid.Cell]image.Point // image sizes of oversized tiles
+	deferred  []int                      // buffer for oversized cells
+
+	padding gruid.Point
+	bgColor color.Color
+
+	clip *sdl.Rect // current clipping rectangle, if any
+
+	rect, trect sdl.Rect           // cell and tile rectangles reused by draw
+	common      [numCommon]texture // textures of common cells (see commonIndex)
+
+	pendingEvent      sdl.Event // event polled while coalescing mouse motion
+	dropSubCellMotion bool
+
+	metricsFunc   func(Metrics)
+	metrics       Metrics   // metrics for the current period
+	metricsStart  time.Time // start of the current period
+	metricsHits   int64     // cache hits at the start of the period
+	metricsMisses int64     // cache misses at the start of the period
+
+	fps    *fpsOverlay // FPS overlay, if enabled
+	fpsKey sdl.Keycode
+
+	renderDriver string // SDL render driver name, if any
+
+	glFunc func(GLFrame)
 }
 
 // Config contains configurations options for the driver.
@@ -61,8 +299,351 @@ type Config struct {
 	Height      int32       // initial screen height in cells (default: 24)
 	Fullscreen  bool        // use “real” fullscreen with a videomode change
 	Accelerated bool        // use accelerated renderer (rarely necessary)
+	VSync       bool        // synchronize presentation with display refresh
 	WindowTitle string      // window title (default: gruid go-sdl2)
 	WindowIcon  image.Image // window icon (optional)
+	Cursor      *Cursor     // mouse cursor (default: system cursor)
+
+	// RenderDriver, if not empty, is the name of the SDL render driver to
+	// use, such as "opengl", "direct3d11", "metal" or "software",
+	// instead of selecting one with the Accelerated option, which is
+	// then ignored. Available drivers are returned by RenderDrivers.
+	RenderDriver string
+
+	// Opacity is the window opacity, between 0 and 1 (default: 1,
+	// opaque). Only whole window opacity is supported, as SDL2 does not
+	// provide per-pixel transparent windows, and it works only on
+	// platforms supporting it, such as X11 with a compositor.
+	Opacity float32
+
+	// ScreenInfo makes the driver report a MsgScreenInfo message after
+	// each gruid.MsgScreen message.
+	ScreenInfo bool
+
+	// ScreenshotDir enables the screenshot key: when the key is pressed,
+	// the driver saves a timestamped PNG screenshot into this directory
+	// and reports a MsgScreenshot message.
+	ScreenshotDir string
+
+	// ScreenshotKey is the key used for screenshots (default: F12).
+	ScreenshotKey sdl.Keycode
+
+	// DebugKeys enables debugging hotkeys handled by the driver: Pause
+	// toggles frame pausing (see SetFramePause) and F10 advances one
+	// frame while paused.
+	DebugKeys bool
+
+	// FPSKey, if not zero, is a key handled by the driver for toggling
+	// the FPS overlay (see SetFPSOverlay).
+	FPSKey sdl.Keycode
+
+	// Timelapse makes the driver start timelapse capture on Init with the
+	// given options, if its Dir field is not empty. See StartTimelapse.
+	Timelapse Timelapse
+
+	// FrameBudget, if positive, makes the driver report a MsgFrameStall
+	// message, and log a warning, when a Flush takes longer than the
+	// given duration. This helps identifying stalls due to slow
+	// TileManagers or texture uploads. See also Driver.FrameHistogram.
+	FrameBudget time.Duration
+
+	// MaxFPS, if positive, limits the number of frames presented per
+	// second: Flush then waits as necessary before drawing a frame, so
+	// that animation-heavy applications do not use a full core. Time
+	// spent waiting is not counted in frame statistics.
+	MaxFPS int
+
+	// TickRate, if positive, makes the driver report MsgTick messages at
+	// the given rate in Hz, providing a heartbeat for real-time games
+	// without the need of a separate goroutine sending messages.
+	TickRate float64
+
+	// FlushDeadline, if positive, enables a watchdog detecting when a
+	// Flush has not completed within the given duration, for example
+	// because of a wedged graphics driver or a blocking TileManager.
+	// OnFlushHang is then called, or diagnostics with goroutine stack
+	// traces are logged if it is nil.
+	FlushDeadline time.Duration
+
+	// OnFlushHang is called by the flush watchdog (see FlushDeadline).
+	// It is called from a separate goroutine, as the main routine is
+	// blocked in Flush.
+	OnFlushHang func(FlushHang)
+
+	// TileWorkers, if greater than 1, is the number of goroutines used
+	// for generating tile images and converting them for texture upload
+	// when a frame has many cache misses. Textures are then created on
+	// the main routine as soon as tiles are ready, reducing hitches when
+	// many unseen tiles come into view. The TileManager's GetImage (or
+	// GetImageErr) method has to be safe for concurrent use in that case:
+	// note that font faces, and thus gruid's tiles.Drawer, usually are
+	// not.
+	TileWorkers int
+
+	// EventHandler, if not nil, is called from the main routine with SDL
+	// events that the driver does not translate into messages, such as
+	// sensor, audio device, or unusual window events. If it returns a
+	// non-nil message, the message is reported by PollMsg.
+	EventHandler func(sdl.Event) gruid.Msg
+
+	// Resizable makes the window resizable by the user. A gruid.MsgScreen
+	// message with the new size in cells is then reported after each
+	// resize, so that the application can adapt its grid.
+	Resizable bool
+
+	// SnapResize makes the driver snap the size of a resizable window to
+	// exact multiples of the tile size, avoiding partial-cell borders.
+	SnapResize bool
+
+	// Backbuffer makes the driver render into an offscreen texture
+	// that is then copied into the window, so that the driver can
+	// present it again by itself after the window was exposed, moved or
+	// restored, instead of reporting a gruid.MsgScreen message for the
+	// application to redraw the screen. It requires a renderer
+	// supporting render targets; otherwise, it is disabled.
+	Backbuffer bool
+
+	// IntegerScale makes the renderer scale the grid by the largest
+	// integer factor fitting in the window, centered with letterbox
+	// bars, so that pixel-art tiles are never blurred or distorted by
+	// fractional scaling. It is most useful with the Resizable or
+	// Fullscreen options: window size changes then scale the grid
+	// instead of changing its size in cells. In this mode, SetScale
+	// sets the window size to a multiple of the grid size, rounding the
+	// scale to an integer.
+	IntegerScale bool
+
+	// ScaleQuality is the filtering used when tiles are scaled, for
+	// example with SetScale: nearest pixel sampling keeps pixel-art
+	// tiles crisp, while linear filtering is smoother for fonts.
+	ScaleQuality ScaleQuality
+
+	// ColorFilter is a color filter applied to tiles, for simulating or
+	// correcting color vision deficiencies (see SetColorFilter).
+	ColorFilter ColorFilter
+
+	// Layout is the placement of cells in the window. Hexagonal layouts
+	// offset every other row or column by half a tile. The isometric
+	// layout places diamond-shaped tiles in a projection where grid rows
+	// go down-left and columns down-right, drawing them back to front;
+	// wide runes are not supported in that layout. Mouse coordinates are
+	// mapped back to cells accordingly.
+	Layout Layout
+
+	// Padding is the size of the margins between the grid and the window
+	// edges, horizontally and vertically, in unscaled pixels. Mouse
+	// coordinates are adjusted accordingly.
+	Padding gruid.Point
+
+	// BackgroundColor is the color of the margins (see Padding), and of
+	// window areas not covered by the grid, such as letterbox bars with
+	// IntegerScale. It is also drawn beneath translucent tiles when there
+	// is no Background image. The default is black.
+	BackgroundColor color.Color
+
+	// Background is an image drawn beneath cells, stretched over the
+	// whole grid, so that it shows through tiles with transparency, as
+	// for parchment backgrounds in menus. A Gradient can be used for a
+	// color gradient.
+	Background image.Image
+
+	// Splash is an image shown immediately after window creation, until
+	// the first Flush, so that long world generation or asset loading
+	// phases do not leave a blank window. It is centered, and scaled down
+	// if it does not fit in the window.
+	Splash image.Image
+
+	// CompactTextures makes the driver create tile textures in 16 bits
+	// formats, RGB565 for opaque tiles and ARGB4444 for tiles with
+	// transparency, cutting texture memory in half for large caches on
+	// memory-constrained devices. Colors are quantized, so it is best
+	// suited to art with a limited palette. It only has an effect if the
+	// renderer supports such formats, as is often the case with OpenGL ES
+	// renderers.
+	CompactTextures bool
+
+	// PacingInterval, if positive, makes the driver report a
+	// MsgPacingStats message with presentation statistics every given
+	// duration, while frames are being flushed.
+	PacingInterval time.Duration
+
+	// ErrorInterval, if positive, makes the driver check every given
+	// duration for rendering failures, such as failed texture creations
+	// or copies, and report a MsgDriverError message if at least
+	// ErrorThreshold failures happened since last check.
+	ErrorInterval time.Duration
+
+	// ErrorThreshold is the number of failures triggering a
+	// MsgDriverError message. Default is 1.
+	ErrorThreshold int
+
+	// WideRunes enables double-width rune handling: wide runes, such as
+	// CJK ideographs, are drawn across their cell and the next one,
+	// whose content is then ignored. Tiles for wide runes may have twice
+	// the normal tile width; otherwise, they are stretched.
+	WideRunes bool
+
+	// GhostAttr is an attribute making the driver draw cells at reduced
+	// opacity (see GhostAlpha), for example for remembered but not
+	// currently visible map cells. The TileManager is asked for the
+	// cell's image without that attribute, so that it does not need to
+	// provide dimmed variants of tiles. A zero value disables the
+	// feature.
+	GhostAttr gruid.AttrMask
+
+	// GhostAlpha is the opacity used for cells with the GhostAttr
+	// attribute (default: 128).
+	GhostAlpha uint8
+
+	// ReverseAttr, UnderlineAttr and BlinkAttr are attributes rendered
+	// by the driver, so that the TileManager does not need to provide
+	// tile variants for every attribute combination. Cells with
+	// ReverseAttr are drawn with foreground and background colors
+	// swapped, cells with UnderlineAttr are drawn with an underline (see
+	// UnderlineColor), and cells with BlinkAttr are drawn blank every
+	// other blinking phase (see BlinkInterval). The TileManager is asked
+	// for images without those attributes. Zero values disable the
+	// features.
+	ReverseAttr   gruid.AttrMask
+	UnderlineAttr gruid.AttrMask
+	BlinkAttr     gruid.AttrMask
+
+	// UnderlineColor returns the color used for underlining a cell with
+	// the given style. If nil, the foreground color from GlyphColors is
+	// used in glyph mode, and white otherwise.
+	UnderlineColor func(gruid.Style) color.Color
+
+	// BlinkInterval is the duration of each blinking phase (default:
+	// 500ms).
+	BlinkInterval time.Duration
+
+	// HideCursor makes the driver hide the mouse cursor on keyboard
+	// input. The cursor is shown again on mouse activity.
+	HideCursor bool
+
+	// CursorIdleTimeout, if positive, makes the driver hide the mouse
+	// cursor after the mouse has been idle for the given duration. The
+	// cursor is shown again on mouse activity.
+	CursorIdleTimeout time.Duration
+
+	// MouseIdleTimeout, if positive, makes the driver report a
+	// MsgMouseIdle message when the mouse has been idle for the given
+	// duration, and another when it becomes active again.
+	MouseIdleTimeout time.Duration
+
+	// DropSubCellMotion makes the driver discard mouse motion events
+	// that stay within the cell under the mouse before any processing,
+	// instead of only not reporting them. Consecutive motion events are
+	// always coalesced into the latest one. With this option, features
+	// following the mouse pixel position, like drag ghosts, only move
+	// when the mouse enters a new cell, and such motion does not count as
+	// mouse activity.
+	DropSubCellMotion bool
+
+	// VirtualKeyboard enables game controller support for an on-screen
+	// keyboard, shown while the application reports an active text input
+	// widget (see SetVirtualKeyboard) and a controller is in use.
+	VirtualKeyboard bool
+
+	// FullscreenKeys enables the Alt+Enter and F11 hotkeys, handled by
+	// the driver, for toggling fullscreen mode. A gruid.MsgScreen message
+	// is reported after each toggle.
+	FullscreenKeys bool
+
+	// ZoomWheel enables zooming with Ctrl+mouse wheel around the cell
+	// under the mouse (see Zoom). Such wheel events are not reported to
+	// the application.
+	ZoomWheel bool
+
+	// SelectionColor is the translucent color used for tinting selected
+	// cells (see SetSelection). The default is a light blue.
+	SelectionColor color.Color
+
+	// HoverColor, if not nil, enables highlighting of the cell under the
+	// mouse, by tinting it with the given translucent color. The highlight
+	// follows mouse motion without application round-trips.
+	HoverColor color.Color
+
+	// PixelToCell, if not nil, overrides how mouse event positions, in
+	// window pixel coordinates, are mapped to cells. It is useful for
+	// letterboxed, offset or multi-viewport layouts. The default mapping
+	// is available as Driver.PixelToCell.
+	PixelToCell func(image.Point) gruid.Point
+
+	// CacheKey, if not nil, returns the key used for caching the texture
+	// of a cell. It can be used to ignore parts of cells that the
+	// TileManager does not render, such as some attributes, preventing
+	// needless cache growth. Cells with the same key should look the
+	// same, as only one texture is created per key. In particular, the
+	// key should keep the GhostAttr attribute.
+	CacheKey func(gruid.Cell) gruid.Cell
+
+	// CacheLimit, if positive, is the maximum number of cached tile
+	// textures. Least recently used textures are evicted after each
+	// Flush when the limit is exceeded, so that long sessions with many
+	// style combinations do not grow memory usage unboundedly.
+	CacheLimit int
+
+	// CacheMemoryLimit, if positive, is like CacheLimit, but limits the
+	// estimated texture memory, in bytes, assuming 32 bits pixels and
+	// tiles of the TileManager's size.
+	//
+	// With the Atlas option, the whole cache is cleared instead when a
+	// limit is exceeded, as atlas space cannot be reclaimed per tile.
+	CacheMemoryLimit int
+
+	// GlyphColors, if not nil, enables glyph mode: tiles are cached per
+	// rune and attributes only, as glyph masks, and colored when drawn,
+	// with the foreground and background colors returned by the
+	// function for a cell's style. It cuts texture memory and cache
+	// misses for colorful interfaces. The TileManager is then asked for
+	// tiles with default colors, which should be drawn as a light glyph
+	// on a dark background: the luminance of each pixel is used as
+	// glyph coverage. Batch tile generation and tile workers are not
+	// used in this mode.
+	GlyphColors func(gruid.Style) (fg, bg color.Color)
+
+	// Atlas makes the driver pack tile textures into a few large atlas
+	// textures, instead of using one texture per tile, reducing the
+	// number of textures and of texture switches when drawing large
+	// grids. Cells are still drawn with one copy each: batching them
+	// into a single geometry draw would require SDL_RenderGeometry,
+	// which needs SDL 2.0.18 and is not provided by the go-sdl2
+	// bindings used. Atlas space is only reclaimed when the cache is
+	// cleared.
+	Atlas bool
+
+	// StreamingTextures makes the driver create tile textures with
+	// streaming access, and reuse the textures of evicted tiles for new
+	// tiles of the same size, updating their pixels instead of allocating
+	// a temporary surface and a new texture on every cache miss. It is
+	// mainly useful along a CacheLimit when tiles change frequently. It
+	// has no effect with the Atlas option.
+	StreamingTextures bool
+
+	// Headless makes the driver render into memory without creating an
+	// actual window, so that no display is required. No input events are
+	// reported in that mode. It is mainly useful for testing.
+	Headless bool
+
+	// RecoverPanics makes the driver recover from panics happening while
+	// drawing a tile, such as in the TileManager's GetImage method. The
+	// panic is reported as an error and a placeholder tile is drawn
+	// instead.
+	RecoverPanics bool
+
+	// ErrorHandler is called with non-fatal errors happening while
+	// drawing. If nil, errors are logged.
+	ErrorHandler func(error)
+
+	// Logger is used for logging driver diagnostics. If nil, the log
+	// package's standard logger is used.
+	Logger *log.Logger
+
+	// TraceInput makes the driver log every translated input message
+	// along with the SDL event it came from, for debugging input
+	// problems.
+	TraceInput bool
 }
 
 // NewDriver returns a new driver with given configuration options.
@@ -80,10 +661,96 @@ func NewDriver(cfg Config) *Driver {
 	if dr.title == "" {
 		dr.title = "gruid go-sdl2"
 	}
+	dr.percent = -1
 	dr.fullscreen = cfg.Fullscreen
 	dr.SetTileManager(cfg.TileManager)
 	dr.accelerated = cfg.Accelerated
+	dr.renderDriver = cfg.RenderDriver
+	dr.vsync = cfg.VSync
 	dr.icon = cfg.WindowIcon
+	dr.cursor = cfg.Cursor
+	dr.recover = cfg.RecoverPanics
+	dr.onError = cfg.ErrorHandler
+	dr.logger = cfg.Logger
+	dr.traceInput = cfg.TraceInput
+	dr.screenInfo = cfg.ScreenInfo
+	dr.debugKeys = cfg.DebugKeys
+	dr.fpsKey = cfg.FPSKey
+	dr.screenshotDir = cfg.ScreenshotDir
+	dr.screenshotKey = cfg.ScreenshotKey
+	if dr.screenshotKey == 0 {
+		dr.screenshotKey = sdl.K_F12
+	}
+	dr.timelapseCfg = cfg.Timelapse
+	dr.frameBudget = cfg.FrameBudget
+	dr.maxFPS = cfg.MaxFPS
+	dr.pacingInterval = cfg.PacingInterval
+	dr.errorInterval = cfg.ErrorInterval
+	dr.errorThreshold = cfg.ErrorThreshold
+	if dr.errorThreshold <= 0 {
+		dr.errorThreshold = 1
+	}
+	dr.tickRate = cfg.TickRate
+	dr.flushDeadline = cfg.FlushDeadline
+	dr.onFlushHang = cfg.OnFlushHang
+	dr.tileWorkers = cfg.TileWorkers
+	dr.eventHandler = cfg.EventHandler
+	dr.resizable = cfg.Resizable
+	dr.snapResize = cfg.SnapResize
+	dr.backbuffer = cfg.Backbuffer
+	dr.integerScale = cfg.IntegerScale
+	dr.scaleQuality = cfg.ScaleQuality
+	dr.colorFilter = cfg.ColorFilter
+	dr.background = cfg.Background
+	dr.layout = cfg.Layout
+	dr.padding = cfg.Padding
+	dr.bgColor = cfg.BackgroundColor
+	dr.intScale = 1
+	dr.brightness = 1
+	dr.splash = cfg.Splash
+	dr.compactTextures = cfg.CompactTextures
+	dr.wideRunes = cfg.WideRunes
+	dr.cacheKey = cfg.CacheKey
+	dr.glyphColors = cfg.GlyphColors
+	dr.atlas = cfg.Atlas
+	dr.streaming = cfg.StreamingTextures && !cfg.Atlas
+	dr.cacheLimit = cfg.CacheLimit
+	dr.cacheMemoryLimit = cfg.CacheMemoryLimit
+	dr.pixelToCell = cfg.PixelToCell
+	dr.hideCursor = cfg.HideCursor
+	dr.fullscreenKeys = cfg.FullscreenKeys
+	dr.zoomWheel = cfg.ZoomWheel
+	dr.hoverColor = cfg.HoverColor
+	dr.selectionColor = cfg.SelectionColor
+	if dr.selectionColor == nil {
+		dr.selectionColor = defaultSelectionColor
+	}
+	dr.cursorIdle = cfg.CursorIdleTimeout
+	dr.mouseIdle = cfg.MouseIdleTimeout
+	dr.dropSubCellMotion = cfg.DropSubCellMotion
+	dr.virtualKeyboard = cfg.VirtualKeyboard
+	dr.ghostAttr = cfg.GhostAttr
+	dr.ghostAlpha = cfg.GhostAlpha
+	dr.reverseAttr = cfg.ReverseAttr
+	dr.underlineAttr = cfg.UnderlineAttr
+	dr.blinkAttr = cfg.BlinkAttr
+	dr.underlineColor = cfg.UnderlineColor
+	dr.blinkInterval = cfg.BlinkInterval
+	if dr.blinkInterval <= 0 {
+		dr.blinkInterval = defaultBlinkInterval
+	}
+	if dr.ghostAlpha == 0 {
+		dr.ghostAlpha = 128
+	}
+	dr.opacity = cfg.Opacity
+	if dr.opacity <= 0 || dr.opacity > 1 {
+		dr.opacity = 1
+	}
+	if cfg.Headless {
+		dr.backend = &headless{}
+	} else {
+		dr.backend = &sdlBackend{}
+	}
 	return dr
 }
 
@@ -119,19 +786,67 @@ func (dr *Driver) SetTileManager(tm TileManager) {
 		}
 	}
 	if dr.init {
-		select {
-		case dr.actions <- fn:
-		default:
-		}
+		dr.queueSet("SetTileManager", fn)
 	} else {
 		fn()
 	}
 }
 
+// action represents an action queued for execution on next Flush.
+type action struct {
+	key string // setter name, for coalescing, or empty
+	fn  func()
+}
+
+// queue queues an action for execution on next Flush. Actions are never
+// dropped.
+func (dr *Driver) queue(fn func()) {
+	dr.actionsMu.Lock()
+	dr.actions = append(dr.actions, action{fn: fn})
+	dr.actionsMu.Unlock()
+}
+
+// queueSet queues an action for execution on next Flush, replacing any
+// pending action with the same key. It is used by setters whose action
+// replaces some state, so that calling them repeatedly before next Flush
+// only keeps the latest setting.
+func (dr *Driver) queueSet(key string, fn func()) {
+	dr.actionsMu.Lock()
+	for i, a := range dr.actions {
+		if a.key == key {
+			dr.actions = append(dr.actions[:i], dr.actions[i+1:]...)
+			dr.stats.coalesceAction()
+			break
+		}
+	}
+	dr.actions = append(dr.actions, action{key: key, fn: fn})
+	dr.actionsMu.Unlock()
+}
+
+// runActions runs queued actions, including those queued by them.
+func (dr *Driver) runActions() {
+	for {
+		dr.actionsMu.Lock()
+		actions := dr.actions
+		dr.actions = nil
+		dr.actionsMu.Unlock()
+		if len(actions) == 0 {
+			return
+		}
+		for _, a := range actions {
+			a.fn()
+		}
+	}
+}
+
 func (dr *Driver) setScale(scaleX, scaleY float32) bool {
+	if dr.integerScale {
+		dr.setIntegerScale(scaleX, scaleY)
+		return true
+	}
 	err := dr.renderer.SetScale(scaleX, scaleY)
 	if err != nil {
-		log.Printf("SetScale: %v", err)
+		dr.logf("SetScale: %v", err)
 		return false
 	}
 	dr.scaleX = scaleX
@@ -141,11 +856,28 @@ func (dr *Driver) setScale(scaleX, scaleY float32) bool {
 }
 
 func (dr *Driver) resizeWindow() {
-	if dr.scaleX > 0.1 && dr.scaleY > 0.1 {
-		dr.window.SetSize(int32(float32(dr.width*dr.tw)*dr.scaleX), int32(float32(dr.height*dr.th)*dr.scaleY))
-	} else {
-		dr.window.SetSize(dr.width*dr.tw, dr.height*dr.th)
+	w, h := dr.windowSize()
+	if dr.integerScale {
+		dr.updateLogicalSize(w, h)
+		w, h = w*dr.intScale, h*dr.intScale
+	} else if dr.scaleX > 0.1 && dr.scaleY > 0.1 {
+		w, h = int32(float32(w)*dr.scaleX), int32(float32(h)*dr.scaleY)
 	}
+	if dr.resizable && !dr.snapResize || dr.integerScale {
+		// Keep the size chosen by the user, unless the window is too
+		// small.
+		cw, ch := dr.window.GetSize()
+		if cw >= w && ch >= h {
+			return
+		}
+		if cw > w {
+			w = cw
+		}
+		if ch > h {
+			h = ch
+		}
+	}
+	dr.window.SetSize(w, h)
 }
 
 // SetScale modifies the rendering scale for rendering, and updates the window
@@ -157,27 +889,93 @@ func (dr *Driver) SetScale(scaleX, scaleY float32) {
 	dr.scaleX = scaleX
 	dr.scaleY = scaleY
 	if dr.init {
-		select {
-		case dr.actions <- fn:
-		default:
-		}
+		dr.queueSet("SetScale", fn)
+	}
+}
+
+// SetFullscreen enables or disables fullscreen mode. If the driver is
+// already running, change will take effect with next Flush so that the
+// function is thread safe.
+func (dr *Driver) SetFullscreen(fullscreen bool) {
+	fn := func() {
+		dr.setFullscreen(fullscreen)
+	}
+	if dr.init {
+		dr.queueSet("SetFullscreen", fn)
+	} else {
+		dr.fullscreen = fullscreen
 	}
 }
 
+func (dr *Driver) setFullscreen(fullscreen bool) {
+	var flags uint32
+	if fullscreen {
+		flags = sdl.WINDOW_FULLSCREEN
+	}
+	err := dr.window.SetFullscreen(flags)
+	if err != nil {
+		dr.logf("set fullscreen: %v", err)
+		return
+	}
+	dr.fullscreen = fullscreen
+}
+
 // SetWindowTitle sets the window title.
 func (dr *Driver) SetWindowTitle(title string) {
 	fn := func() {
-		dr.window.SetTitle(title)
+		dr.window.SetTitle(dr.windowTitle())
 	}
 	dr.title = title
 	if dr.init {
-		select {
-		case dr.actions <- fn:
-		default:
-		}
+		dr.queueSet("SetWindowTitle", fn)
+	}
+}
+
+// SetOpacity sets the window opacity, between 0 (transparent) and 1
+// (opaque). See Config.Opacity.
+func (dr *Driver) SetOpacity(opacity float32) {
+	fn := func() {
+		dr.setOpacity()
+	}
+	dr.opacity = opacity
+	if dr.init {
+		dr.queueSet("SetOpacity", fn)
+	}
+}
+
+func (dr *Driver) setOpacity() {
+	err := dr.window.SetWindowOpacity(dr.opacity)
+	if err != nil {
+		dr.logf("set opacity: %v", err)
+	}
+}
+
+// SetTitlePercent prefixes the window title with a fraction between 0 and 1
+// shown as a percentage, as in "[42%] title". A negative fraction removes the
+// prefix. It only changes the title, so it is visible wherever the title is,
+// such as in the taskbar or dock entry of the window.
+func (dr *Driver) SetTitlePercent(fraction float64) {
+	if fraction > 1 {
+		fraction = 1
+	}
+	fn := func() {
+		dr.window.SetTitle(dr.windowTitle())
+	}
+	dr.percent = fraction
+	if dr.init {
+		dr.queueSet("SetTitlePercent", fn)
 	}
 }
 
+// windowTitle returns the window title, including the percentage prefix, if
+// any.
+func (dr *Driver) windowTitle() string {
+	if dr.percent < 0 {
+		return dr.title
+	}
+	return fmt.Sprintf("[%d%%] %s", int(dr.percent*100), dr.title)
+}
+
 // PreventQuit will make next call to Close keep sdl and the main window
 // running. It can be used to chain two applications with the same sdl session
 // and window. It is then your reponsibility to either run another application
@@ -190,7 +988,6 @@ func (dr *Driver) PreventQuit() {
 // sdl.Init().
 func (dr *Driver) Init() error {
 	dr.reqredraw = make(chan bool, 1)
-	dr.actions = make(chan func(), 4)
 	if dr.tm == nil {
 		return errors.New("no tile manager provided")
 	}
@@ -198,44 +995,73 @@ func (dr *Driver) Init() error {
 	if dr.init {
 		dr.resizeWindow()
 	} else {
-		if err = sdl.Init(sdl.INIT_VIDEO); err != nil {
+		if err = dr.backend.init(); err != nil {
 			return err
 		}
-		dr.window, err = sdl.CreateWindow(dr.title, sdl.WINDOWPOS_UNDEFINED, sdl.WINDOWPOS_UNDEFINED,
-			dr.width*dr.tw, dr.height*dr.th, sdl.WINDOW_SHOWN)
+		dr.setScaleQuality()
+		gw, gh := dr.gridSize()
+		dr.window, err = dr.backend.createWindow(dr.windowTitle(), gw, gh, sdl.WINDOW_SHOWN)
 		if err != nil {
 			return fmt.Errorf("failed to create sdl window: %v", err)
 		}
-		if dr.accelerated {
-			dr.renderer, err = sdl.CreateRenderer(dr.window, -1, sdl.RENDERER_ACCELERATED)
-		} else {
-			dr.renderer, err = sdl.CreateRenderer(dr.window, -1, sdl.RENDERER_SOFTWARE)
-		}
+		dr.renderer, err = dr.backend.createRenderer(dr.window, dr.renderDriver, dr.rendererFlags())
 		if err != nil {
 			return fmt.Errorf("failed to create sdl renderer: %v", err)
 		}
-		dr.window.SetResizable(false)
+		dr.window.SetResizable(dr.resizable)
+		if dr.integerScale {
+			w, h := dr.windowSize()
+			dr.updateLogicalSize(w, h)
+		}
+		if dr.compactTextures {
+			dr.setCompactTextures()
+		}
+		if dr.position != nil {
+			dr.window.SetPosition(int32(dr.position.X), int32(dr.position.Y))
+		}
+		if dr.size != nil && dr.resizable {
+			dr.window.SetSize(int32(dr.size.X), int32(dr.size.Y))
+		}
 		dr.setIcon()
+		if dr.cursor != nil {
+			dr.setCursor()
+		}
+		if dr.opacity < 1 {
+			dr.setOpacity()
+		}
 		if dr.fullscreen {
-			err := dr.window.SetFullscreen(sdl.WINDOW_FULLSCREEN)
-			if err != nil {
-				log.Printf("set fullscreen: %v", err)
-			}
+			dr.setFullscreen(true)
 		}
 		if dr.scaleX > 0.1 || dr.scaleY > 0.1 {
 			dr.setScale(dr.scaleX, dr.scaleY)
 		}
-		err := dr.renderer.Clear()
+		err := dr.clear()
 		if err != nil {
-			log.Printf("renderer clear: %v", err)
+			dr.logf("renderer clear: %v", err)
+		}
+		if dr.splash != nil {
+			dr.drawSplash()
+			dr.renderer.Present()
+			dr.splashed = true
+		}
+		dr.backend.startTextInput()
+		if dr.virtualKeyboard {
+			err := dr.backend.initControllers()
+			if err != nil {
+				dr.logf("controllers: %v", err)
+			}
 		}
-		sdl.StartTextInput()
-		rect := sdl.Rect{X: 0, Y: 0, W: 100, H: 100}
-		sdl.SetTextInputRect(&rect)
 	}
-	dr.textures = make(map[gruid.Cell]*sdl.Texture)
+	dr.textures = make(map[gruid.Cell]texture)
 	dr.mousedrag = -1
 	dr.init = true
+	dr.updateRefreshRate()
+	if dr.timelapseCfg.Dir != "" && dr.timelapse == nil {
+		if err := dr.StartTimelapse(dr.timelapseCfg); err != nil {
+			dr.logf("%v", err)
+		}
+	}
+	dr.startTicker()
 	return nil
 }
 
@@ -245,18 +1071,48 @@ func (dr *Driver) setIcon() {
 	}
 	sf, err := imageToSurface(dr.icon)
 	if err != nil {
-		log.Printf("bad icon image: %v", err)
+		dr.logf("bad icon image: %v", err)
 		return
 	}
 	dr.window.SetIcon(sf)
 }
 
+// coords returns the cell position for mouse event pixel coordinates.
 func (dr *Driver) coords(x, y int32) gruid.Point {
+	p := image.Point{X: int(x), Y: int(y)}
+	if dr.pixelToCell != nil {
+		return dr.pixelToCell(p)
+	}
+	return dr.PixelToCell(p)
+}
+
+// PixelToCell returns the cell position containing the given position in
+// window pixel coordinates, taking into account the rendering scale, tile
+// size and layout. It is the default mapping used for mouse events, which can
+// be overridden with Config.PixelToCell.
+func (dr *Driver) PixelToCell(p image.Point) gruid.Point {
+	x, y := float32(p.X), float32(p.Y)
 	if dr.scaleX > 0.1 && dr.scaleY > 0.1 {
-		x = int32(float32(x) / dr.scaleX)
-		y = int32(float32(y) / dr.scaleY)
+		x /= dr.scaleX
+		y /= dr.scaleY
+	}
+	x, y = dr.unzoom(x, y)
+	return dr.layoutCell(x, y)
+}
+
+// SetPixelToCell changes the function used for mapping mouse event pixel
+// coordinates to cells (see Config.PixelToCell). A nil function restores the
+// default mapping. If the driver is already running, change will take
+// effect with next Flush so that the function is thread safe.
+func (dr *Driver) SetPixelToCell(fn func(image.Point) gruid.Point) {
+	set := func() {
+		dr.pixelToCell = fn
+	}
+	if dr.init {
+		dr.queueSet("SetPixelToCell", set)
+	} else {
+		set()
 	}
-	return gruid.Point{X: int((x - 1) / dr.tw), Y: int((y - 1) / dr.th)}
 }
 
 // PollMsg makes Driver implement gruid.DriverPollMsg. It returns return an
@@ -265,46 +1121,168 @@ func (dr *Driver) PollMsg() (gruid.Msg, error) {
 	for {
 		select {
 		case <-dr.reqredraw:
-			w, h := dr.window.GetSize()
-			return gruid.MsgScreen{Width: int(w / dr.tw), Height: int(h / dr.th), Time: time.Now()}, nil
+			return dr.screenMsg(), nil
 		default:
 		}
-		event := sdl.PollEvent()
+		if dr.needRefresh {
+			dr.refresh()
+		}
+		dr.checkCursorIdle()
+		dr.checkMouseIdle()
+		dr.checkErrors()
+		dr.checkShake()
+		dr.checkTransition()
+		dr.checkAnimations()
+		dr.checkBlink()
+		dr.checkMetrics()
+		dr.checkFPS()
+		if len(dr.msgs) > 0 {
+			msg := dr.msgs[0]
+			dr.msgs = dr.msgs[1:]
+			return msg, nil
+		}
+		event := dr.nextEvent()
 		if event == nil {
 			return nil, nil
 		}
-		var msg gruid.Msg
-		switch ev := event.(type) {
-		case *sdl.QuitEvent:
-			msg = gruid.MsgQuit(time.Now())
-		case *sdl.TextInputEvent:
-			msg = dr.pollTextInputEvent(ev)
-		//case *sdl.TextEditingEvent:
-		// TODO: Handling this would allow to use an input
-		// method for making compositions and chosing text.
-		// I'm not sure what the API for this should be in
-		// gruid or the driver.
-		case *sdl.KeyboardEvent:
-			msg = dr.pollKeyboardEvent(ev)
-		case *sdl.MouseButtonEvent:
+		msg := dr.handleEvent(event)
+		if msg == nil {
+			continue
+		}
+		return msg, nil
+	}
+}
+
+// WaitMsg is a blocking variant of PollMsg. It waits until an input message
+// is available or the timeout expires, in which case a nil message is
+// returned. A negative timeout means waiting indefinitely. As with PollMsg,
+// it should be called from the main routine. It can be used by simple
+// applications that do not use gruid.App.
+func (dr *Driver) WaitMsg(timeout time.Duration) (gruid.Msg, error) {
+	var deadline time.Time
+	if timeout >= 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		msg, err := dr.PollMsg()
+		if msg != nil || err != nil {
+			return msg, err
+		}
+		ms := -1
+		if timeout >= 0 {
+			d := time.Until(deadline)
+			if d <= 0 {
+				return nil, nil
+			}
+			ms = int((d + time.Millisecond - 1) / time.Millisecond)
+		}
+		event := dr.coalesceMotion(dr.backend.waitEvent(ms))
+		if event == nil {
+			continue
+		}
+		msg = dr.handleEvent(event)
+		if msg != nil {
+			return msg, nil
+		}
+	}
+}
+
+// passEvent passes an untranslated event to the configured event handler, if
+// any, and returns its message.
+func (dr *Driver) passEvent(event sdl.Event) gruid.Msg {
+	if dr.eventHandler == nil {
+		return nil
+	}
+	return dr.eventHandler(event)
+}
+
+// handleEvent translates an SDL event into a gruid message, if any.
+func (dr *Driver) handleEvent(event sdl.Event) gruid.Msg {
+	dr.stats.addEvent()
+	dr.updateCursor(event)
+	dr.updateMouseIdle(event)
+	dr.trackMouse(event)
+	msg, ok := dr.viewportMouseEvent(event)
+	switch ev := event.(type) {
+	case *sdl.QuitEvent:
+		msg = gruid.MsgQuit(time.Now())
+	case *sdl.TextInputEvent:
+		msg = dr.pollTextInputEvent(ev)
+	//case *sdl.TextEditingEvent:
+	// TODO: Handling this would allow to use an input
+	// method for making compositions and chosing text.
+	// I'm not sure what the API for this should be in
+	// gruid or the driver.
+	case *sdl.KeyboardEvent:
+		if dr.virtualKeyboard {
+			dr.pollControllerEvent(ev)
+		}
+		msg = dr.pollKeyboardEvent(ev)
+	case *sdl.ControllerDeviceEvent, *sdl.ControllerButtonEvent:
+		if dr.virtualKeyboard {
+			msg = dr.pollControllerEvent(ev)
+		} else {
+			msg = dr.passEvent(ev)
+		}
+	case *sdl.MouseButtonEvent:
+		if !ok {
 			msg = dr.pollMouseButtonEvent(ev)
-		case *sdl.MouseMotionEvent:
+		}
+	case *sdl.MouseMotionEvent:
+		if !ok {
 			msg = dr.pollMouseMotionEvent(ev)
-		case *sdl.MouseWheelEvent:
+		}
+	case *sdl.MouseWheelEvent:
+		if !ok {
 			msg = dr.pollMouseWheelEvent(ev)
-		case *sdl.WindowEvent:
-			msg = dr.pollWindowEvent(ev)
 		}
+	case *sdl.WindowEvent:
+		msg = dr.pollWindowEvent(ev)
 		if msg == nil {
-			continue
+			msg = dr.passEvent(ev)
+		}
+	case *sdl.RenderEvent:
+		dr.pollRenderEvent(ev)
+	case *sdl.UserEvent:
+		if tick := dr.pollUserEvent(ev); tick != nil {
+			// Ticks are not input messages.
+			return *tick
+		}
+		msg = dr.passEvent(ev)
+	default:
+		msg = dr.passEvent(ev)
+	}
+	if msg != nil {
+		dr.stats.addMsg()
+		dr.inputs.add(time.Now(), msg)
+		if dr.traceInput {
+			dr.logf("input [%s]: %T %+v -> %T %+v", time.Now().Format("15:04:05.000000"), event, event, msg, msg)
 		}
-		return msg, nil
 	}
+	return msg
 }
 
-// PollMsgs implements gruid.Driver.PollMsgs.
+const (
+	pollInterval    = 2 * time.Millisecond   // event wait timeout on activity
+	maxPollInterval = 100 * time.Millisecond // maximum event wait timeout
+	idleDelay       = time.Second            // inactivity delay before backoff
+)
+
+// PollMsgs implements gruid.Driver.PollMsgs. It waits for events with
+// sdl.WaitEventTimeout, so that input is handled as soon as it arrives, while
+// the timeout bounds the delay for handling cancellation, redraw requests and
+// idle checks. When no messages nor frames have been seen for a while, the
+// timeout is progressively increased to reduce CPU usage, and it drops back
+// to a short one on activity.
+//
+// Note that gruid.App never calls PollMsgs: as the driver implements
+// gruid.DriverPollMsg, App calls PollMsg from the main routine instead, with
+// its own fixed polling interval. The waiting described here thus only
+// applies when the driver is used without gruid.App, in a loop calling
+// PollMsgs or WaitMsg.
 func (dr *Driver) PollMsgs(ctx context.Context, msgs chan<- gruid.Msg) error {
-	var t *time.Timer
+	interval := pollInterval
+	lastMsg := time.Now()
 	for {
 		msg, err := dr.PollMsg()
 		select {
@@ -314,20 +1292,20 @@ func (dr *Driver) PollMsgs(ctx context.Context, msgs chan<- gruid.Msg) error {
 			if err != nil {
 				return err
 			}
+		}
+		if msg == nil {
+			interval = dr.nextPollInterval(interval, lastMsg)
+			event := dr.coalesceMotion(dr.backend.waitEvent(int(interval / time.Millisecond)))
+			if event == nil {
+				continue
+			}
+			msg = dr.handleEvent(event)
 			if msg == nil {
-				if t == nil {
-					t = time.NewTimer(2 * time.Millisecond)
-				} else {
-					t.Reset(2 * time.Millisecond)
-				}
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case <-t.C:
-					continue
-				}
+				continue
 			}
 		}
+		lastMsg = time.Now()
+		interval = pollInterval
 		select {
 		case <-ctx.Done():
 			return nil
@@ -336,6 +1314,33 @@ func (dr *Driver) PollMsgs(ctx context.Context, msgs chan<- gruid.Msg) error {
 	}
 }
 
+// nextPollInterval returns the polling interval to be used after interval,
+// given the time of the last message. It is only used by PollMsgs, so that the
+// idle backoff does not apply with gruid.App, which uses PollMsg.
+func (dr *Driver) nextPollInterval(interval time.Duration, lastMsg time.Time) time.Duration {
+	last := dr.stats.lastFrame()
+	if lastMsg.After(last) {
+		last = lastMsg
+	}
+	if dr.suspended {
+		return maxPollInterval
+	}
+	if dr.shaking() || dr.transition.kind != 0 {
+		return pollInterval
+	}
+	if (len(dr.animFrames) > 0 || len(dr.blinking) > 0) && interval*2 > effectInterval {
+		return effectInterval
+	}
+	if time.Since(last) < idleDelay {
+		return pollInterval
+	}
+	interval *= 2
+	if interval > maxPollInterval {
+		interval = maxPollInterval
+	}
+	return interval
+}
+
 func (dr *Driver) pollTextInputEvent(ev *sdl.TextInputEvent) gruid.Msg {
 	s := ev.GetText()
 	if utf8.RuneCountInString(s) != 1 {
@@ -357,6 +1362,9 @@ func (dr *Driver) pollKeyboardEvent(ev *sdl.KeyboardEvent) gruid.Msg {
 	if ev.Type == sdl.KEYUP {
 		return nil
 	}
+	if msg, ok := dr.hotkey(ev); ok {
+		return msg
+	}
 	msg := gruid.MsgKeyDown{}
 	if sdl.KMOD_LALT&ev.Keysym.Mod != 0 {
 		msg.Mod |= gruid.ModAlt
@@ -435,6 +1443,38 @@ func (dr *Driver) pollKeyboardEvent(ev *sdl.KeyboardEvent) gruid.Msg {
 	return msg
 }
 
+// hotkey handles keys intercepted by the driver. It reports whether the key
+// was handled, in which case the returned message, if any, should be
+// reported instead of the key.
+func (dr *Driver) hotkey(ev *sdl.KeyboardEvent) (gruid.Msg, bool) {
+	c := ev.Keysym.Sym
+	switch {
+	case dr.screenshotDir != "" && c == dr.screenshotKey:
+		if ev.Repeat != 0 {
+			return nil, true
+		}
+		return dr.screenshotMsg(), true
+	case dr.fullscreenKeys && (c == sdl.K_F11 || c == sdl.K_RETURN && ev.Keysym.Mod&sdl.KMOD_ALT != 0):
+		if ev.Repeat != 0 {
+			return nil, true
+		}
+		dr.setFullscreen(!dr.fullscreen)
+		return dr.screenMsg(), true
+	case dr.debugKeys && c == sdl.K_PAUSE:
+		dr.setFramePause(!dr.paused)
+		return nil, true
+	case dr.debugKeys && c == sdl.K_F10:
+		dr.StepFrame()
+		return nil, true
+	case dr.fpsKey != 0 && c == dr.fpsKey:
+		if ev.Repeat == 0 {
+			dr.setFPSOverlay(dr.fps == nil)
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
 func (dr *Driver) pollMouseButtonEvent(ev *sdl.MouseButtonEvent) gruid.Msg {
 	var action gruid.MouseAction
 	switch ev.Button {
@@ -461,6 +1501,9 @@ func (dr *Driver) pollMouseButtonEvent(ev *sdl.MouseButtonEvent) gruid.Msg {
 		msg.Time = time.Now()
 		msg.Action = action
 		dr.mousedrag = action
+		if dr.inspector {
+			dr.inspect(msg.P)
+		}
 	case sdl.MOUSEBUTTONUP:
 		if dr.mousedrag != action {
 			return nil
@@ -473,19 +1516,7 @@ func (dr *Driver) pollMouseButtonEvent(ev *sdl.MouseButtonEvent) gruid.Msg {
 		msg.Action = gruid.MouseRelease
 		dr.mousedrag = -1
 	}
-	mod := sdl.GetModState()
-	if sdl.KMOD_LALT&mod != 0 {
-		msg.Mod |= gruid.ModAlt
-	}
-	if sdl.KMOD_LSHIFT&mod != 0 || sdl.KMOD_RSHIFT&mod != 0 {
-		msg.Mod |= gruid.ModShift
-	}
-	if sdl.KMOD_LCTRL&mod != 0 || sdl.KMOD_RCTRL&mod != 0 {
-		msg.Mod |= gruid.ModCtrl
-	}
-	if sdl.KMOD_RGUI&mod != 0 {
-		msg.Mod |= gruid.ModMeta
-	}
+	msg.Mod = dr.mouseMod()
 	dr.mousepos = msg.P
 	return msg
 }
@@ -503,23 +1534,36 @@ func (dr *Driver) pollMouseMotionEvent(ev *sdl.MouseMotionEvent) gruid.Msg {
 	msg.Time = time.Now()
 	msg.Action = gruid.MouseMove
 	dr.mousepos = msg.P
-	mod := sdl.GetModState()
-	if sdl.KMOD_LALT&mod != 0 {
-		msg.Mod |= gruid.ModAlt
+	if dr.inspector || dr.hoverColor != nil || dr.magnifierFollowsMouse() {
+		dr.needRefresh = true
 	}
-	if sdl.KMOD_LSHIFT&mod != 0 || sdl.KMOD_RSHIFT&mod != 0 {
-		msg.Mod |= gruid.ModShift
+	msg.Mod = dr.mouseMod()
+	return msg
+}
+
+// mouseMod returns the modifiers for mouse messages.
+func (dr *Driver) mouseMod() gruid.ModMask {
+	var mod gruid.ModMask
+	state := dr.backend.modState()
+	if sdl.KMOD_LALT&state != 0 {
+		mod |= gruid.ModAlt
 	}
-	if sdl.KMOD_LCTRL&mod != 0 || sdl.KMOD_RCTRL&mod != 0 {
-		msg.Mod |= gruid.ModCtrl
+	if sdl.KMOD_LSHIFT&state != 0 || sdl.KMOD_RSHIFT&state != 0 {
+		mod |= gruid.ModShift
 	}
-	if sdl.KMOD_RGUI&mod != 0 {
-		msg.Mod |= gruid.ModMeta
+	if sdl.KMOD_LCTRL&state != 0 || sdl.KMOD_RCTRL&state != 0 {
+		mod |= gruid.ModCtrl
 	}
-	return msg
+	if sdl.KMOD_RGUI&state != 0 {
+		mod |= gruid.ModMeta
+	}
+	return mod
 }
 
 func (dr *Driver) pollMouseWheelEvent(ev *sdl.MouseWheelEvent) gruid.Msg {
+	if dr.wheelZoom(ev) {
+		return nil
+	}
 	msg := gruid.MsgMouse{}
 	if ev.Y > 0 {
 		msg.Action = gruid.MouseWheelUp
@@ -533,28 +1577,73 @@ func (dr *Driver) pollMouseWheelEvent(ev *sdl.MouseWheelEvent) gruid.Msg {
 	return msg
 }
 
+// screenMsg returns a gruid.MsgScreen for current window size. If enabled,
+// a MsgScreenInfo is queued too.
+func (dr *Driver) screenMsg() gruid.Msg {
+	// The application redraws the whole screen after such a message,
+	// and the window content may be lost.
+	dr.drawn = false
+	dr.invalidateViewports()
+	w, h := dr.window.GetSize()
+	t := time.Now()
+	if dr.screenInfo {
+		info := MsgScreenInfo{
+			TileSize:  gruid.Point{X: int(dr.tw), Y: int(dr.th)},
+			PixelSize: gruid.Point{X: int(w), Y: int(h)},
+			ScaleX:    1,
+			ScaleY:    1,
+			Time:      t,
+		}
+		if dr.scaleX > 0.1 && dr.scaleY > 0.1 {
+			info.ScaleX, info.ScaleY = dr.scaleX, dr.scaleY
+		}
+		dpi, err := dr.backend.displayDPI(dr.window)
+		if err != nil {
+			dr.logf("display DPI: %v", err)
+		}
+		info.DPI = dpi
+		dr.msgs = append(dr.msgs, info)
+	}
+	if dr.integerScale {
+		// The grid is scaled to fit the window.
+		return gruid.MsgScreen{Width: int(dr.width), Height: int(dr.height), Time: t}
+	}
+	px, py := int32(dr.padding.X), int32(dr.padding.Y)
+	if dr.scaleX > 0.1 && dr.scaleY > 0.1 {
+		px, py = int32(float32(px)*dr.scaleX), int32(float32(py)*dr.scaleY)
+	}
+	if w > 2*px && h > 2*py {
+		// Margins are not available for the grid.
+		w, h = w-2*px, h-2*py
+	}
+	tw, th := dr.scaledTileSize()
+	return gruid.MsgScreen{Width: int(w / tw), Height: int(h / th), Time: t}
+}
+
 func (dr *Driver) pollWindowEvent(ev *sdl.WindowEvent) gruid.Msg {
 	switch ev.Event {
 	case sdl.WINDOWEVENT_EXPOSED:
-		w, h := dr.window.GetSize()
-		return gruid.MsgScreen{Width: int(w / dr.tw), Height: int(h / dr.th), Time: time.Now()}
+		dr.resume()
+		if dr.represent() {
+			return nil
+		}
+		return dr.screenMsg()
 		//log.Print("exposed")
-		//case sdl.WINDOWEVENT_SHOWN:
-		//log.Print("shown")
-		//case sdl.WINDOWEVENT_HIDDEN:
-		//log.Print("hidden")
-		//case sdl.WINDOWEVENT_MOVED:
-		//log.Print("moved")
+	case sdl.WINDOWEVENT_SHOWN, sdl.WINDOWEVENT_MAXIMIZED:
+		dr.resume()
+	case sdl.WINDOWEVENT_HIDDEN, sdl.WINDOWEVENT_MINIMIZED:
+		dr.suspend()
+	case sdl.WINDOWEVENT_MOVED:
+		dr.represent()
+		return dr.updateRefreshRate()
 		//case sdl.WINDOWEVENT_RESIZED:
 		//log.Print("resized")
-		//case sdl.WINDOWEVENT_SIZE_CHANGED:
-		//log.Print("size changed")
-		//case sdl.WINDOWEVENT_MINIMIZED:
-		//log.Print("minimized")
-		//case sdl.WINDOWEVENT_MAXIMIZED:
-		//log.Print("maximized")
-		//case sdl.WINDOWEVENT_RESTORED:
-		//log.Print("restored")
+	case sdl.WINDOWEVENT_SIZE_CHANGED:
+		return dr.windowResized()
+	case sdl.WINDOWEVENT_RESTORED:
+		if !dr.resume() {
+			dr.represent()
+		}
 		//case sdl.WINDOWEVENT_ENTER:
 		//log.Print("enter")
 		//case sdl.WINDOWEVENT_LEAVE:
@@ -575,77 +1664,426 @@ func (dr *Driver) pollWindowEvent(ev *sdl.WindowEvent) gruid.Msg {
 	return nil
 }
 
-// Flush implements gruid.Driver.Flush.
+// DriverFlushErr is an optional extension of gruid.Driver implemented by
+// Driver for reporting drawing errors to the application.
+type DriverFlushErr interface {
+	gruid.Driver
+
+	// FlushErr is like Flush, but returns drawing errors instead of
+	// reporting them to the error handler.
+	FlushErr(gruid.Frame) error
+}
+
+// FlushError is the error returned by FlushErr when some cells could not be
+// drawn.
+type FlushError struct {
+	Err   error // first error
+	Count int   // number of cells that could not be drawn
+}
+
+func (e *FlushError) Error() string {
+	if e.Count > 1 {
+		return fmt.Sprintf("%v (and %d other errors)", e.Err, e.Count-1)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the first error.
+func (e *FlushError) Unwrap() error {
+	return e.Err
+}
+
+// Flush implements gruid.Driver.Flush. Drawing errors are reported to the
+// error handler.
 func (dr *Driver) Flush(frame gruid.Frame) {
-actions:
-	for {
-		select {
-		case fn := <-dr.actions:
-			fn()
-		default:
-			break actions
-		}
+	err := dr.FlushErr(frame)
+	if err != nil {
+		dr.handleError(err)
+	}
+}
+
+// FlushErr implements DriverFlushErr.FlushErr. It returns a *FlushError if
+// some cells could not be drawn, so that applications can detect persistent
+// rendering failures, such as a lost graphics device.
+func (dr *Driver) FlushErr(frame gruid.Frame) error {
+	start := time.Now()
+	if dr.flushDeadline > 0 {
+		defer dr.watchFlush(len(frame.Cells), start)()
 	}
+	dr.runActions()
+	if dr.paused {
+		dr.pauseFrame(frame)
+		return nil
+	}
+	if _, ok := dr.renderer.(lostRenderer); ok {
+		return ErrRendererLost
+	}
+	return dr.flush(frame, start)
+}
+
+// flush draws and presents a frame, for a Flush started at the given time.
+func (dr *Driver) flush(frame gruid.Frame, start time.Time) error {
 	if frame.Width != int(dr.width) || frame.Height != int(dr.height) {
 		dr.width = int32(frame.Width)
 		dr.height = int32(frame.Height)
 		dr.resizeWindow()
 	}
+	if dr.suspended {
+		dr.storeFrame(frame)
+		dr.stats.addSkipped()
+		dr.streamFrame(frame)
+		dr.castFrame(frame)
+		return nil
+	}
+	diff := dr.diffFrame(frame)
+	if len(diff.Cells) == 0 && !dr.hasOverlays() && !dr.overlaid && !dr.splashed && !dr.fullRedraw {
+		dr.stats.addSkipped()
+		// New stream clients still get their full frame.
+		dr.streamFrame(frame)
+		return nil
+	}
+	if dr.maxFPS > 0 {
+		start = dr.limitFrameRate(start)
+	}
+	if dr.hooks.BeforeFlush != nil {
+		dr.hooks.BeforeFlush(frame, start)
+	}
+	drawStart := time.Now()
+	dr.clearSplash()
+	dr.beginDraw()
+	drawn := dr.metrics.Cells
+	ferr := dr.drawFrame(diff)
+	drawn = dr.metrics.Cells - drawn
+	dr.drawn = true
+	dr.evict()
+	dr.updateCacheStats()
+	presentStart := time.Now()
+	dr.present()
+	end := time.Now()
+	dr.stats.addFrame(end.Sub(start), len(dr.textures))
+	dr.stats.addSample(frameSample{total: end.Sub(start), present: end.Sub(presentStart)})
+	stall := dr.checkBudget(MsgFrameStall{
+		Duration: end.Sub(start),
+		Draw:     presentStart.Sub(drawStart),
+		Present:  end.Sub(presentStart),
+		Time:     end,
+	})
+	dr.addPresented(frame, end, stall)
+	dr.addMetricsFrame(end.Sub(start))
+	dr.addFPSFrame(end.Sub(start))
+	if dr.hooks.AfterPresent != nil {
+		dr.hooks.AfterPresent(FrameInfo{
+			Start:   start,
+			Draw:    presentStart.Sub(drawStart),
+			Present: end.Sub(presentStart),
+			Cells:   drawn,
+		})
+	}
+	dr.captureTimelapse(end)
+	dr.streamFrame(frame)
+	dr.castFrame(frame)
+	dr.slowDown(end)
+	if ferr != nil {
+		return ferr
+	}
+	return nil
+}
+
+// drawFrame draws the frame's cells. If overlays are or were visible, the
+// whole grid is redrawn instead, followed by the overlays.
+func (dr *Driver) drawFrame(frame gruid.Frame) *FlushError {
+	w, h := int(dr.width), int(dr.height)
+	if len(dr.grid) != w*h {
+		dr.grid = make([]gruid.Cell, w*h)
+	}
+	iso := dr.layout == IsometricLayout
+	dr.prefetch(frame, dr.hasOverlays() || dr.overlaid || dr.fullRedraw || iso)
+	if dr.wideRunes && !iso {
+		return dr.drawFrameWide(frame)
+	}
+	// Drawing of the whole grid is restricted to changed regions when
+	// possible.
+	var clip sdl.Rect
+	clipping := (iso || len(dr.oversized) > 0) && dr.canClip()
+	if clipping {
+		clip, clipping = dr.dirtyRect(frame)
+	}
 	for _, fc := range frame.Cells {
-		cs := fc.Cell
-		x, y := fc.P.X, fc.P.Y
-		dr.draw(cs, x, y)
+		if fc.P.X >= 0 && fc.P.X < w && fc.P.Y >= 0 && fc.P.Y < h {
+			dr.grid[fc.P.X+w*fc.P.Y] = fc.Cell
+		}
+	}
+	var ferr *FlushError
+	overlays := dr.hasOverlays()
+	full := dr.fullRedraw
+	dr.fullRedraw = false
+	if clipping {
+		dr.setClip(&clip)
+	}
+	oversized := len(dr.oversized)
+	if iso {
+		ferr = dr.drawIsometric()
+	} else if overlays || dr.overlaid || full || len(dr.oversized) > 0 {
+		ferr = dr.drawGrid()
+	} else {
+		for _, fc := range frame.Cells {
+			cs := fc.Cell
+			x, y := fc.P.X, fc.P.Y
+			ferr = addError(ferr, dr.draw(cs, x, y))
+		}
+	}
+	if dr.clip != nil {
+		dr.setClip(nil)
+	}
+	if len(dr.oversized) > oversized {
+		// Newly found oversized tiles might extend beyond the
+		// clipping rectangle, or have been overlapped by neighbors
+		// drawn afterwards.
+		if iso {
+			ferr = mergeErrors(ferr, dr.drawIsometric())
+		} else {
+			ferr = mergeErrors(ferr, dr.drawGrid())
+		}
 	}
-	dr.renderer.Present()
+	dr.drawPadding()
+	if overlays {
+		dr.drawOverlays()
+	}
+	dr.overlaid = overlays
+	return ferr
+}
+
+// addError records a drawing error, if not nil, into ferr, allocating it if
+// necessary.
+func addError(ferr *FlushError, err error) *FlushError {
+	if err == nil {
+		return ferr
+	}
+	if ferr == nil {
+		ferr = &FlushError{Err: err}
+	}
+	ferr.Count++
+	return ferr
+}
+
+// mergeErrors merges the errors of a second drawing pass over the whole grid
+// into those of the first pass, keeping the first error. As the second pass
+// redraws the cells of the first, the largest count is kept.
+func mergeErrors(ferr, ferr2 *FlushError) *FlushError {
+	if ferr == nil {
+		return ferr2
+	}
+	if ferr2 != nil && ferr2.Count > ferr.Count {
+		ferr.Count = ferr2.Count
+	}
+	return ferr
 }
 
+// imageToSurface returns a new 32 bits surface with the image's pixels,
+// keeping the alpha channel. Textures created from it are alpha-blended,
+// unless the image is opaque.
 func imageToSurface(img image.Image) (*sdl.Surface, error) {
-	buf := bytes.Buffer{}
-	err := bmp.Encode(&buf, img)
+	m, opaque := toNRGBA(img)
+	w, h := m.Rect.Dx(), m.Rect.Dy()
+	sf, err := sdl.CreateRGBSurfaceWithFormat(0, int32(w), int32(h), 32, uint32(sdl.PIXELFORMAT_RGBA32))
 	if err != nil {
 		return nil, err
 	}
-	src, err := sdl.RWFromMem(buf.Bytes())
-	if err != nil {
-		return nil, err
+	if w > 0 && h > 0 {
+		pix, pitch := sf.Pixels(), int(sf.Pitch)
+		for y := 0; y < h; y++ {
+			copy(pix[y*pitch:y*pitch+4*w], m.Pix[y*m.Stride:])
+		}
 	}
-	sf, err := sdl.LoadBMPRW(src, true)
-	if err != nil {
-		return nil, err
+	if opaque {
+		err = sf.SetBlendMode(sdl.BLENDMODE_NONE)
+		if err != nil {
+			sf.Free()
+			return nil, err
+		}
 	}
 	return sf, nil
 }
 
-func (dr *Driver) draw(cell gruid.Cell, x, y int) {
-	var tx *sdl.Texture
-	if t, ok := dr.textures[cell]; ok {
-		tx = t
-	} else {
-		img := dr.tm.GetImage(cell)
-		if img == nil {
-			log.Printf("no tile for %+v", cell)
-			return
+// toNRGBA returns an image as an *image.NRGBA with origin at zero,
+// converting it if necessary, and reports whether it is opaque.
+func toNRGBA(img image.Image) (*image.NRGBA, bool) {
+	img = unwrapImage(img)
+	b := img.Bounds()
+	m, ok := img.(*image.NRGBA)
+	if !ok || b.Min != (image.Point{}) {
+		m = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(m, m.Rect, img, b.Min, draw.Src)
+	}
+	return m, m.Opaque()
+}
+
+func (dr *Driver) draw(cell gruid.Cell, x, y int) (err error) {
+	if dr.recover {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("draw: panic for %+v: %v", cell, r)
+				dr.drawPlaceholder(x, y)
+			}
+		}()
+	}
+	dr.metrics.Cells++
+	cell, underline := dr.renderAttrs(cell, x+int(dr.width)*y)
+	ghost := cell.Style.Attrs&dr.ghostAttr != 0
+	key := dr.key(cell)
+	tx, animated := dr.animationFrame(cell, key, x+int(dr.width)*y)
+	ok := animated
+	if !ok {
+		tx, ok = dr.lookupTexture(key)
+	}
+	if !ok {
+		c := cell
+		if ghost {
+			c.Style.Attrs &^= dr.ghostAttr
+		}
+		var img image.Image
+		if dr.glyphColors != nil {
+			img, err = dr.getGlyph(c)
+		} else {
+			img, err = dr.getImage(c)
 		}
-		sf, err := imageToSurface(img)
 		if err != nil {
-			log.Println(err)
-			return
+			return err
 		}
-		tx, err = dr.renderer.CreateTextureFromSurface(sf)
+		tx, err = dr.newTexture(img)
 		if err != nil {
-			log.Println(err)
-			return
+			dr.addTextureFailure()
+			return fmt.Errorf("draw: texture: %v", err)
+		}
+		dr.setTileSize(key, img.Bounds().Size())
+		if dr.glyphColors != nil {
+			err = tx.SetBlendMode(sdl.BLENDMODE_BLEND)
+			if err != nil {
+				tx.Destroy()
+				return fmt.Errorf("draw: glyph texture: %v", err)
+			}
+		}
+		if ghost {
+			err = dr.ghostTexture(tx)
+			if err != nil {
+				tx.Destroy()
+				return err
+			}
 		}
-		sf.Free()
-		dr.textures[cell] = tx
+		dr.storeTexture(key, tx)
+		dr.cache.Misses++
+	} else {
+		dr.cache.Hits++
+	}
+	if !animated {
+		dr.touch(key)
+	}
+	// The rectangles are stored in the driver, so that passing them to the
+	// renderer does not allocate.
+	rect := &dr.rect
+	*rect = dr.cellRect(x, y)
+	if dr.wideRunes && isWide(cell.Rune) && int32(x) < dr.width-1 {
+		rect.W *= 2
+	}
+	if (ghost || dr.background != nil) && dr.glyphColors == nil && dr.layout != IsometricLayout {
+		// Clear previous content, as the tile may be translucent.
+		err = dr.clearCell(rect)
+		if err != nil {
+			return fmt.Errorf("draw: clear: %v", err)
+		}
+	}
+	dr.trect = dr.tileRect(key, *rect)
+	err = dr.copyTile(cell, tx, &dr.trect)
+	if err != nil {
+		dr.addCopyFailure()
+		return fmt.Errorf("draw: copy: %v", err)
+	}
+	if underline {
+		err = dr.drawUnderline(cell.Style, *rect)
+		if err != nil {
+			return err
+		}
+	}
+	if dr.cellOverlay != nil {
+		return dr.drawCellOverlay(gruid.Point{X: x, Y: y}, rect)
+	}
+	return nil
+}
+
+// ghostTexture makes a texture translucent, for drawing ghost cells.
+func (dr *Driver) ghostTexture(tx texture) error {
+	err := tx.SetBlendMode(sdl.BLENDMODE_BLEND)
+	if err == nil {
+		err = tx.SetAlphaMod(dr.ghostAlpha)
+	}
+	if err != nil {
+		return fmt.Errorf("draw: ghost texture: %v", err)
+	}
+	return nil
+}
+
+// key returns the texture cache key for a cell.
+func (dr *Driver) key(c gruid.Cell) gruid.Cell {
+	if dr.glyphColors != nil {
+		c = glyphCell(c)
+	}
+	if dr.cacheKey != nil {
+		return dr.cacheKey(c)
+	}
+	return c
+}
+
+// getImage returns the TileManager's image for a cell, or a *TileError.
+func (dr *Driver) getImage(cell gruid.Cell) (image.Image, error) {
+	img, err := getTile(dr.tm, cell)
+	if err != nil || img == nil {
+		return nil, &TileError{Cell: cell, Err: err}
+	}
+	return img, nil
+}
+
+// getTile returns a TileManager's image for a cell, using GetImageErr if
+// available.
+func getTile(tm TileManager, cell gruid.Cell) (image.Image, error) {
+	if tm, ok := tm.(TileManagerErr); ok {
+		return tm.GetImageErr(cell)
+	}
+	return tm.GetImage(cell), nil
+}
+
+// drawPlaceholder draws a placeholder tile at the given cell position, in
+// place of a tile that could not be drawn.
+func (dr *Driver) drawPlaceholder(x, y int) {
+	rect := dr.cellRect(x, y)
+	err := dr.renderer.SetDrawColor(255, 0, 255, 255)
+	if err == nil {
+		err = dr.renderer.FillRect(&rect)
 	}
-	rect := sdl.Rect{X: int32(x) * dr.tw, Y: int32(y) * dr.th, W: dr.tw, H: dr.th}
-	err := dr.renderer.Copy(tx, nil, &rect)
 	if err != nil {
-		log.Printf("draw: copy: %v", err)
+		dr.logf("draw: placeholder: %v", err)
 	}
 }
 
+// handleError reports a non-fatal error using the configured error handler,
+// if any, or logs it otherwise.
+func (dr *Driver) handleError(err error) {
+	if dr.onError != nil {
+		dr.onError(err)
+		return
+	}
+	dr.logf("%v", err)
+}
+
+// logf logs a message with the configured logger.
+func (dr *Driver) logf(format string, v ...interface{}) {
+	dr.logs.add(time.Now(), fmt.Sprintf(format, v...))
+	if dr.logger != nil {
+		dr.logger.Printf(format, v...)
+		return
+	}
+	log.Printf(format, v...)
+}
+
 // Close implements gruid.Driver.Close. It releases some resources and calls sdl.Quit.
 func (dr *Driver) Close() {
 	if !dr.init {
@@ -653,17 +2091,42 @@ func (dr *Driver) Close() {
 	}
 	dr.ClearCache()
 	dr.textures = nil
+	dr.msgs = nil
+	for _, vp := range dr.viewports {
+		vp.ClearCache()
+	}
+	dr.viewports = nil
+	dr.vpDrag = nil
+	dr.clearDragGhost()
+	dr.clearCRT()
+	dr.clearAnimations()
+	dr.clearSprites()
+	dr.clearBackground()
+	dr.endTransition()
+	dr.destroyBackbuffer()
 	if !dr.noQuit {
-		sdl.StopTextInput()
+		if path, err := dr.StopTimelapse(); err != nil {
+			dr.logf("%v", err)
+		} else if path != "" {
+			dr.logf("timelapse written to %s", path)
+		}
+		if err := dr.StopStream(); err != nil {
+			dr.logf("%v", err)
+		}
+		if err := dr.StopCast(); err != nil {
+			dr.logf("%v", err)
+		}
+		dr.stopTicker()
+		dr.backend.stopTextInput()
 		err := dr.renderer.Destroy()
 		if err != nil {
-			log.Printf("renderer destroy: %v", err)
+			dr.logf("renderer destroy: %v", err)
 		}
 		err = dr.window.Destroy()
 		if err != nil {
-			log.Printf("window destroy: %v", err)
+			dr.logf("window destroy: %v", err)
 		}
-		sdl.Quit()
+		dr.backend.quit()
 		dr.init = false
 	}
 	dr.noQuit = false
@@ -674,8 +2137,20 @@ func (dr *Driver) ClearCache() {
 	for i, s := range dr.textures {
 		err := s.Destroy()
 		if err != nil {
-			log.Printf("surface destroy: %v", err)
+			dr.logf("surface destroy: %v", err)
 		}
 		delete(dr.textures, i)
 	}
+	dr.common = [numCommon]texture{}
+	dr.clearAtlas()
+	dr.clearStreamPool()
+	dr.lru = cacheLRU{}
+	dr.minimapColors = nil
+	dr.clearCRT()
+	dr.clearAnimations()
+	dr.clearSprites()
+	dr.clearBackground()
+	dr.oversized = nil
+	dr.drawn = false
+	dr.updateCacheStats()
 }
@@ -0,0 +1,321 @@
+package sdl
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// testTileManager draws tiles as a uniform color depending on the cell, with
+// a square of the foreground color for non-space runes. Wide runes get
+// double-width tiles.
+type testTileManager struct{}
+
+const testTileSize = 8
+
+func (tm testTileManager) GetImage(c gruid.Cell) image.Image {
+	w := testTileSize
+	if isWide(c.Rune) {
+		w *= 2
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, testTileSize))
+	draw.Draw(img, img.Rect, image.NewUniform(testColor(c.Rune, c.Style.Bg)), image.Point{}, draw.Src)
+	if c.Rune != ' ' {
+		fg := image.Rect(2, 2, w-2, testTileSize-2)
+		draw.Draw(img, fg, image.NewUniform(testColor(c.Rune, c.Style.Fg+1)), image.Point{}, draw.Src)
+	}
+	return img
+}
+
+func (tm testTileManager) TileSize() gruid.Point {
+	return gruid.Point{X: testTileSize, Y: testTileSize}
+}
+
+func testColor(r rune, c gruid.Color) color.RGBA {
+	x := (uint32(r) + 31*uint32(c)) * 2654435761
+	return color.RGBA{R: uint8(x >> 24), G: uint8(x >> 16), B: uint8(x >> 8), A: 0xff}
+}
+
+// newTestDriver returns an initialized headless driver with the given
+// configuration, along with its backend.
+func newTestDriver(t *testing.T, cfg Config) (*Driver, *headless) {
+	t.Helper()
+	cfg.Headless = true
+	if cfg.TileManager == nil {
+		cfg.TileManager = testTileManager{}
+	}
+	if cfg.Width == 0 {
+		cfg.Width, cfg.Height = 10, 5
+	}
+	dr := NewDriver(cfg)
+	if err := dr.Init(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(dr.Close)
+	// Discard messages reported on initialization.
+	for {
+		msg, err := dr.PollMsg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg == nil {
+			break
+		}
+	}
+	return dr, dr.backend.(*headless)
+}
+
+// testFrame returns a frame with the given cells, whose runes are given
+// row by row.
+func testFrame(w, h int, rows ...string) gruid.Frame {
+	frame := gruid.Frame{Width: w, Height: h}
+	for y, row := range rows {
+		x := 0
+		for _, r := range row {
+			frame.Cells = append(frame.Cells, gruid.FrameCell{P: gruid.Point{X: x, Y: y}, Cell: gruid.Cell{Rune: r}})
+			x++
+		}
+	}
+	return frame
+}
+
+// screenshot returns the current rendering.
+func screenshot(t *testing.T, dr *Driver) *image.RGBA {
+	t.Helper()
+	img, err := dr.Screenshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := image.NewRGBA(img.Bounds())
+	draw.Draw(m, m.Rect, img, img.Bounds().Min, draw.Src)
+	return m
+}
+
+// sameImages reports whether two images have the same pixels, and returns
+// the first differing point otherwise.
+func sameImages(a, b *image.RGBA) (image.Point, bool) {
+	if a.Rect != b.Rect {
+		return a.Rect.Max, false
+	}
+	for y := a.Rect.Min.Y; y < a.Rect.Max.Y; y++ {
+		for x := a.Rect.Min.X; x < a.Rect.Max.X; x++ {
+			if a.RGBAAt(x, y) != b.RGBAAt(x, y) {
+				return image.Point{X: x, Y: y}, false
+			}
+		}
+	}
+	return image.Point{}, true
+}
+
+// pollAll injects the given events and returns the reported messages, with
+// their time cleared.
+func pollAll(t *testing.T, dr *Driver, hl *headless, mod sdl.Keymod, events ...sdl.Event) []gruid.Msg {
+	t.Helper()
+	hl.inject(mod, events...)
+	var msgs []gruid.Msg
+	for {
+		msg, err := dr.PollMsg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch m := msg.(type) {
+		case nil:
+			return msgs
+		case gruid.MsgKeyDown:
+			m.Time = time.Time{}
+			msg = m
+		case gruid.MsgMouse:
+			m.Time = time.Time{}
+			msg = m
+		case gruid.MsgQuit:
+			msg = gruid.MsgQuit{}
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+func keyDown(sym sdl.Keycode, mod sdl.Keymod) *sdl.KeyboardEvent {
+	return &sdl.KeyboardEvent{Type: sdl.KEYDOWN, Keysym: sdl.Keysym{Sym: sym, Mod: uint16(mod)}}
+}
+
+func textInput(s string) *sdl.TextInputEvent {
+	ev := &sdl.TextInputEvent{Type: sdl.TEXTINPUT}
+	copy(ev.Text[:], s)
+	return ev
+}
+
+func TestKeyboardEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		ev   sdl.Event
+		want gruid.Msg // nil if no message
+	}{
+		{"arrow", keyDown(sdl.K_DOWN, 0), gruid.MsgKeyDown{Key: gruid.KeyArrowDown}},
+		{"escape", keyDown(sdl.K_ESCAPE, 0), gruid.MsgKeyDown{Key: gruid.KeyEscape}},
+		{"shift", keyDown(sdl.K_TAB, sdl.KMOD_LSHIFT), gruid.MsgKeyDown{Key: gruid.KeyTab, Mod: gruid.ModShift}},
+		{"ctrl", keyDown(sdl.K_HOME, sdl.KMOD_RCTRL), gruid.MsgKeyDown{Key: gruid.KeyHome, Mod: gruid.ModCtrl}},
+		{"alt", keyDown(sdl.K_RETURN, sdl.KMOD_LALT), gruid.MsgKeyDown{Key: gruid.KeyEnter, Mod: gruid.ModAlt}},
+		{"keypad", keyDown(sdl.K_KP_8, 0), gruid.MsgKeyDown{Key: gruid.KeyArrowUp}},
+		{"keypad numlock", keyDown(sdl.K_KP_8, sdl.KMOD_NUM), nil},
+		{"letter", keyDown(sdl.K_a, 0), nil},
+		{"key up", &sdl.KeyboardEvent{Type: sdl.KEYUP, Keysym: sdl.Keysym{Sym: sdl.K_DOWN}}, nil},
+		{"text", textInput("a"), gruid.MsgKeyDown{Key: "a"}},
+		{"text unicode", textInput("é"), gruid.MsgKeyDown{Key: "é"}},
+		{"text several", textInput("ab"), nil},
+		{"quit", &sdl.QuitEvent{Type: sdl.QUIT}, gruid.MsgQuit{}},
+	}
+	for _, tt := range tests {
+		dr, hl := newTestDriver(t, Config{})
+		msgs := pollAll(t, dr, hl, 0, tt.ev)
+		switch {
+		case tt.want == nil && len(msgs) > 0:
+			t.Errorf("%s: messages = %v, want none", tt.name, msgs)
+		case tt.want != nil && (len(msgs) != 1 || msgs[0] != tt.want):
+			t.Errorf("%s: messages = %v, want %v", tt.name, msgs, tt.want)
+		}
+	}
+}
+
+func TestMouseEvents(t *testing.T) {
+	motion := func(x, y int32) sdl.Event {
+		return &sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: x, Y: y}
+	}
+	button := func(typ uint32, b uint8, x, y int32) sdl.Event {
+		return &sdl.MouseButtonEvent{Type: typ, Button: b, X: x, Y: y}
+	}
+	wheel := func(y int32) sdl.Event {
+		return &sdl.MouseWheelEvent{Type: sdl.MOUSEWHEEL, Y: y}
+	}
+	// The steps are run in order with the same driver.
+	tests := []struct {
+		name string
+		ev   sdl.Event
+		mod  sdl.Keymod
+		want gruid.Msg // nil if no message
+	}{
+		{"move", motion(12, 3), 0, gruid.MsgMouse{Action: gruid.MouseMove, P: gruid.Point{X: 1, Y: 0}}},
+		{"move same cell", motion(15, 7), 0, nil},
+		{"move outside", motion(200, 3), 0, nil},
+		{"move shift", motion(20, 10), sdl.KMOD_LSHIFT, gruid.MsgMouse{Action: gruid.MouseMove, P: gruid.Point{X: 2, Y: 1}, Mod: gruid.ModShift}},
+		{"press", button(sdl.MOUSEBUTTONDOWN, sdl.BUTTON_LEFT, 20, 10), 0, gruid.MsgMouse{Action: gruid.MouseMain, P: gruid.Point{X: 2, Y: 1}}},
+		{"press during drag", button(sdl.MOUSEBUTTONDOWN, sdl.BUTTON_RIGHT, 20, 10), 0, nil},
+		{"release other", button(sdl.MOUSEBUTTONUP, sdl.BUTTON_RIGHT, 20, 10), 0, nil},
+		{"release", button(sdl.MOUSEBUTTONUP, sdl.BUTTON_LEFT, 33, 10), 0, gruid.MsgMouse{Action: gruid.MouseRelease, P: gruid.Point{X: 4, Y: 1}}},
+		{"press secondary", button(sdl.MOUSEBUTTONDOWN, sdl.BUTTON_RIGHT, 0, 0), 0, gruid.MsgMouse{Action: gruid.MouseSecondary}},
+		{"release outside", button(sdl.MOUSEBUTTONUP, sdl.BUTTON_RIGHT, 200, 0), 0, gruid.MsgMouse{Action: gruid.MouseRelease}},
+		{"press outside", button(sdl.MOUSEBUTTONDOWN, sdl.BUTTON_MIDDLE, 0, 100), 0, nil},
+		{"press auxiliary", button(sdl.MOUSEBUTTONDOWN, sdl.BUTTON_MIDDLE, 9, 9), sdl.KMOD_RCTRL, gruid.MsgMouse{Action: gruid.MouseAuxiliary, P: gruid.Point{X: 1, Y: 1}, Mod: gruid.ModCtrl}},
+		{"wheel up", wheel(1), 0, gruid.MsgMouse{Action: gruid.MouseWheelUp, P: gruid.Point{X: 1, Y: 1}}},
+		{"wheel down", wheel(-2), 0, gruid.MsgMouse{Action: gruid.MouseWheelDown, P: gruid.Point{X: 1, Y: 1}}},
+		{"wheel horizontal", &sdl.MouseWheelEvent{Type: sdl.MOUSEWHEEL, X: 1}, 0, nil},
+	}
+	dr, hl := newTestDriver(t, Config{})
+	for _, tt := range tests {
+		msgs := pollAll(t, dr, hl, tt.mod, tt.ev)
+		switch {
+		case tt.want == nil && len(msgs) > 0:
+			t.Errorf("%s: messages = %v, want none", tt.name, msgs)
+		case tt.want != nil && (len(msgs) != 1 || msgs[0] != tt.want):
+			t.Errorf("%s: messages = %v, want %v", tt.name, msgs, tt.want)
+		}
+	}
+}
+
+func TestPixelToCell(t *testing.T) {
+	tests := []struct {
+		scale float32
+		p     image.Point
+		want  gruid.Point
+	}{
+		{0, image.Point{}, gruid.Point{}},
+		{0, image.Point{X: 7, Y: 7}, gruid.Point{}},
+		{0, image.Point{X: 8, Y: 0}, gruid.Point{X: 1, Y: 0}},
+		{0, image.Point{X: 15, Y: 16}, gruid.Point{X: 1, Y: 2}},
+		{0, image.Point{X: -1, Y: -1}, gruid.Point{X: -1, Y: -1}},
+		{2, image.Point{X: 15, Y: 15}, gruid.Point{}},
+		{2, image.Point{X: 16, Y: 0}, gruid.Point{X: 1, Y: 0}},
+		{2, image.Point{X: 47, Y: 32}, gruid.Point{X: 2, Y: 2}},
+	}
+	for _, tt := range tests {
+		dr, _ := newTestDriver(t, Config{})
+		if tt.scale > 0 {
+			dr.SetScale(tt.scale, tt.scale)
+			dr.Flush(testFrame(10, 5))
+		}
+		if got := dr.PixelToCell(tt.p); got != tt.want {
+			t.Errorf("scale %v: PixelToCell(%v) = %v, want %v", tt.scale, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestCustomPixelToCell(t *testing.T) {
+	fn := func(p image.Point) gruid.Point {
+		return gruid.Point{X: p.X / 2, Y: p.Y / 2}
+	}
+	dr, hl := newTestDriver(t, Config{PixelToCell: fn})
+	msgs := pollAll(t, dr, hl, 0, &sdl.MouseMotionEvent{Type: sdl.MOUSEMOTION, X: 6, Y: 4})
+	want := gruid.MsgMouse{Action: gruid.MouseMove, P: gruid.Point{X: 3, Y: 2}}
+	if len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("messages = %v, want %v", msgs, want)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	var got []int
+	for i := 0; i < 20; i++ {
+		i := i
+		dr.queue(func() { got = append(got, i) })
+	}
+	dr.queue(func() {
+		// Actions queued by actions run with the same Flush.
+		dr.queue(func() { got = append(got, 20) })
+	})
+	for i := 0; i < 10; i++ {
+		dr.SetWindowTitle("title")
+	}
+	dr.Flush(testFrame(10, 5))
+	if len(got) != 21 {
+		t.Fatalf("ran %d actions, want 21", len(got))
+	}
+	for i, v := range got {
+		if v != i {
+			t.Fatalf("action %d ran in position %d", v, i)
+		}
+	}
+	if dr.title != "title" {
+		t.Errorf("title = %q, want %q", dr.title, "title")
+	}
+	if n := dr.Stats().CoalescedActions; n != 9 {
+		t.Errorf("coalesced %d actions, want 9", n)
+	}
+	if len(dr.actions) != 0 {
+		t.Errorf("%d actions left in queue", len(dr.actions))
+	}
+}
+
+func TestTitlePercent(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	win := dr.window.(*headlessWindow)
+	tests := []struct {
+		fraction float64
+		want     string
+	}{
+		{0.42, "[42%] title"},
+		{2, "[100%] title"},
+		{-1, "title"},
+	}
+	dr.SetWindowTitle("title")
+	for _, tt := range tests {
+		dr.SetTitlePercent(tt.fraction)
+		dr.Flush(testFrame(10, 5))
+		if win.title != tt.want {
+			t.Errorf("fraction %v: title %q, want %q", tt.fraction, win.title, tt.want)
+		}
+	}
+}
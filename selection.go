@@ -0,0 +1,82 @@
+package sdl
+
+import (
+	"image/color"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// defaultSelectionColor is the default color used for tinting the selection.
+var defaultSelectionColor = color.RGBA{R: 80, G: 140, B: 255, A: 96}
+
+// SetSelection highlights a rectangular region of cells, by tinting them
+// with the selection color (see Config.SelectionColor), on top of the
+// existing tiles. This is useful for mouse text selection or area targeting,
+// without the need of flushing restyled cells. An empty range removes the
+// selection. It should be called from the main routine, for example from the
+// Update method of a gruid.Model. The selection is drawn the next time
+// messages are polled, or on next Flush.
+func (dr *Driver) SetSelection(rg gruid.Range) {
+	if rg.Empty() && dr.selection.Empty() || rg == dr.selection {
+		return
+	}
+	dr.selection = rg
+	dr.needRefresh = true
+}
+
+// Selection returns the currently highlighted region of cells.
+func (dr *Driver) Selection() gruid.Range {
+	return dr.selection
+}
+
+// drawSelection tints the selected cells.
+func (dr *Driver) drawSelection() {
+	rg := dr.selection.Intersect(gruid.NewRange(0, 0, int(dr.width), int(dr.height)))
+	if rg.Empty() {
+		return
+	}
+	dr.tintRect(rg, dr.selectionColor)
+}
+
+// tintRect draws a translucent color over a range of cells.
+func (dr *Driver) tintRect(rg gruid.Range, c color.Color) {
+	r, g, b, a := c.RGBA()
+	// Color values are alpha-premultiplied.
+	if a > 0 {
+		r, g, b = r*0xffff/a, g*0xffff/a, b*0xffff/a
+	}
+	rd := dr.renderer
+	rd.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	defer rd.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+	rd.SetDrawColor(uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8))
+	if dr.layout != GridLayout {
+		rects := make([]sdl.Rect, 0, rg.Size().X*rg.Size().Y)
+		rg.Iter(func(p gruid.Point) {
+			rects = append(rects, dr.cellRect(p.X, p.Y))
+		})
+		err := rd.FillRects(rects)
+		if err != nil {
+			dr.logf("tint: %v", err)
+		}
+		return
+	}
+	rect := sdl.Rect{
+		X: int32(rg.Min.X)*dr.tw + int32(dr.padding.X),
+		Y: int32(rg.Min.Y)*dr.th + int32(dr.padding.Y),
+		W: int32(rg.Max.X-rg.Min.X) * dr.tw,
+		H: int32(rg.Max.Y-rg.Min.Y) * dr.th,
+	}
+	err := rd.FillRect(&rect)
+	if err != nil {
+		dr.logf("tint: %v", err)
+	}
+}
+
+// drawHover tints the cell under the mouse.
+func (dr *Driver) drawHover() {
+	if _, ok := dr.cellAt(dr.mousepos); !ok {
+		return
+	}
+	dr.tintRect(gruid.Range{Min: dr.mousepos, Max: dr.mousepos.Add(gruid.Point{X: 1, Y: 1})}, dr.hoverColor)
+}
@@ -0,0 +1,92 @@
+package sdl
+
+import (
+	"math/rand"
+	"time"
+)
+
+// effectInterval is the interval between presents during effects, when the
+// application does not flush new frames.
+const effectInterval = 16 * time.Millisecond
+
+// shake describes an ongoing screen shake effect.
+type shake struct {
+	start     time.Time
+	duration  time.Duration
+	magnitude float32 // initial maximum offset, in pixels
+}
+
+// Shake shakes the rendered content for the given duration, by applying a
+// random pixel offset to presented frames, up to magnitude pixels, decaying
+// linearly with time. The driver presents new offsets on its own while
+// shaking, so that the effect is visible even if the application does not
+// flush new frames. Shaking draws into an offscreen backbuffer, as with
+// Config.Backbuffer. Mouse coordinates are not affected. If the driver is
+// already running, change will take effect with next Flush so that the
+// function is thread safe.
+func (dr *Driver) Shake(duration time.Duration, magnitude float32) {
+	fn := func() {
+		if duration <= 0 || magnitude <= 0 {
+			dr.stopShake()
+			return
+		}
+		if !dr.usesBackbuffer() {
+			// The backbuffer is not up to date.
+			dr.fullRedraw = true
+			dr.needRefresh = true
+		}
+		dr.shake = shake{start: time.Now(), duration: duration, magnitude: magnitude}
+	}
+	if dr.init {
+		dr.queue(fn)
+	} else {
+		fn()
+	}
+}
+
+// shaking reports whether a shake effect is ongoing.
+func (dr *Driver) shaking() bool {
+	return dr.shake.duration > 0
+}
+
+// stopShake ends the shake effect, if any, presenting the content at its
+// normal position.
+func (dr *Driver) stopShake() {
+	if !dr.shaking() {
+		return
+	}
+	dr.shake = shake{}
+	if !dr.represent() {
+		dr.fullRedraw = true
+		dr.needRefresh = true
+	}
+}
+
+// checkShake presents the content with a new offset while shaking, and ends
+// the effect when its duration elapsed.
+func (dr *Driver) checkShake() {
+	if !dr.shaking() || dr.suspended {
+		return
+	}
+	now := time.Now()
+	if now.Sub(dr.shake.start) >= dr.shake.duration {
+		dr.stopShake()
+		return
+	}
+	if now.Sub(dr.presented) >= effectInterval {
+		dr.represent()
+	}
+}
+
+// shakeOffset returns a new random offset for the next present.
+func (dr *Driver) shakeOffset() (int32, int32) {
+	if !dr.shaking() {
+		return 0, 0
+	}
+	elapsed := time.Since(dr.shake.start)
+	if elapsed >= dr.shake.duration {
+		return 0, 0
+	}
+	m := dr.shake.magnitude * float32(dr.shake.duration-elapsed) / float32(dr.shake.duration)
+	return int32((rand.Float32()*2 - 1) * m), int32((rand.Float32()*2 - 1) * m)
+}
@@ -0,0 +1,117 @@
+package sdl
+
+import (
+	"image"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// drawSplash clears the window and draws the splash image, if any, centered
+// and scaled down to fit the grid's area if necessary.
+func (dr *Driver) drawSplash() {
+	err := dr.renderer.SetDrawColor(0, 0, 0, 255)
+	if err == nil {
+		err = dr.renderer.Clear()
+	}
+	if err != nil {
+		dr.logf("splash: %v", err)
+		return
+	}
+	if dr.splash == nil {
+		return
+	}
+	tx, err := dr.renderer.createTexture(dr.splash)
+	if err != nil {
+		dr.logf("splash: %v", err)
+		return
+	}
+	defer tx.Destroy()
+	size := dr.splash.Bounds().Size()
+	w, h := dr.width*dr.tw, dr.height*dr.th
+	rect := fitRect(image.Pt(int(w), int(h)), size)
+	err = dr.renderer.copy(tx, nil, &rect)
+	if err != nil {
+		dr.logf("splash: %v", err)
+	}
+}
+
+// fitRect returns a rectangle with the given size centered in an area,
+// scaled down to fit if necessary, keeping the aspect ratio.
+func fitRect(area, size image.Point) sdl.Rect {
+	w, h := size.X, size.Y
+	if w > area.X && w > 0 {
+		w, h = area.X, h*area.X/w
+	}
+	if h > area.Y && h > 0 {
+		w, h = w*area.Y/h, area.Y
+	}
+	return sdl.Rect{X: int32((area.X - w) / 2), Y: int32((area.Y - h) / 2), W: int32(w), H: int32(h)}
+}
+
+// clearSplash clears the window if it shows the splash image, before drawing
+// the first frame.
+func (dr *Driver) clearSplash() {
+	if !dr.splashed {
+		return
+	}
+	dr.splashed = false
+	err := dr.renderer.SetDrawColor(0, 0, 0, 255)
+	if err == nil {
+		err = dr.renderer.Clear()
+	}
+	if err != nil {
+		dr.logf("splash: %v", err)
+	}
+}
+
+// DrawProgress draws a progress bar with a label, on top of the splash image
+// if any (see Config.Splash), and presents it, so that initialization work
+// can show progress without bootstrapping a full grid and model. The
+// fraction should be between 0 and 1. It should be called from the main
+// routine, after Init and before the first Flush, for example before
+// starting a gruid.App. Input events received meanwhile are kept for
+// PollMsg.
+func (dr *Driver) DrawProgress(label string, fraction float64) {
+	if !dr.init {
+		return
+	}
+	if fraction < 0 {
+		fraction = 0
+	} else if fraction > 1 {
+		fraction = 1
+	}
+	dr.drawSplash()
+	dr.splashed = true
+	w, h := dr.width*dr.tw, dr.height*dr.th
+	bw, bh := w*2/3, int32(2*glyphHeight)
+	if bw < 10 {
+		bw = w
+	}
+	x, y := (w-bw)/2, h*3/4-bh/2
+	r := dr.renderer
+	r.SetDrawColor(255, 255, 255, 255)
+	r.FillRect(&sdl.Rect{X: x - 1, Y: y - 1, W: bw + 2, H: bh + 2})
+	r.SetDrawColor(48, 48, 48, 255)
+	r.FillRect(&sdl.Rect{X: x, Y: y, W: bw, H: bh})
+	r.SetDrawColor(80, 140, 255, 255)
+	r.FillRect(&sdl.Rect{X: x, Y: y, W: int32(float64(bw) * fraction), H: bh})
+	if label != "" {
+		scale := int32(2)
+		lw, lh := textSize(label)
+		if lw*scale > w {
+			scale = 1
+		}
+		r.SetDrawColor(255, 255, 255, 255)
+		dr.drawTextScale((w-lw*scale)/2, y-lh*scale-4, scale, label)
+	}
+	r.Present()
+	for {
+		event := dr.backend.pollEvent()
+		if event == nil {
+			break
+		}
+		if msg := dr.handleEvent(event); msg != nil {
+			dr.msgs = append(dr.msgs, msg)
+		}
+	}
+}
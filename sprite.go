@@ -0,0 +1,108 @@
+package sdl
+
+import (
+	"image"
+	"sort"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Sprite is an image drawn on top of the grid at an arbitrary pixel
+// position, such as a pixel-positioned cursor, a projectile, or a floating
+// damage number.
+type Sprite struct {
+	// Image is the sprite's image. Textures are cached by image, so the
+	// same image value should be reused across frames. Images should not
+	// be modified after use.
+	Image image.Image
+
+	// P is the position of the sprite's top-left corner, in unscaled
+	// window pixel coordinates.
+	P image.Point
+
+	// Z is the sprite's z-order: sprites with greater Z are drawn on top
+	// of the others. Sprites with equal Z are drawn in order.
+	Z int
+}
+
+// SetSprites replaces the sprites drawn on top of the grid on each Flush. A
+// nil or empty slice removes all sprites. Textures for images no longer in
+// use are released. If the driver is already running, change will take
+// effect with next Flush so that the function is thread safe.
+func (dr *Driver) SetSprites(sprites []Sprite) {
+	sps := make([]Sprite, len(sprites))
+	copy(sps, sprites)
+	sort.SliceStable(sps, func(i, j int) bool { return sps[i].Z < sps[j].Z })
+	fn := func() {
+		dr.sprites = sps
+		dr.pruneSprites()
+	}
+	if dr.init {
+		dr.queueSet("SetSprites", fn)
+	} else {
+		fn()
+	}
+}
+
+// pruneSprites releases textures of images not used by current sprites.
+func (dr *Driver) pruneSprites() {
+	if len(dr.spriteTextures) == 0 {
+		return
+	}
+	used := make(map[image.Image]bool, len(dr.sprites))
+	for _, sp := range dr.sprites {
+		used[sp.Image] = true
+	}
+	for img, tx := range dr.spriteTextures {
+		if used[img] {
+			continue
+		}
+		err := tx.Destroy()
+		if err != nil {
+			dr.logf("sprite destroy: %v", err)
+		}
+		delete(dr.spriteTextures, img)
+	}
+}
+
+// clearSprites releases all sprite textures.
+func (dr *Driver) clearSprites() {
+	for img, tx := range dr.spriteTextures {
+		err := tx.Destroy()
+		if err != nil {
+			dr.logf("sprite destroy: %v", err)
+		}
+		delete(dr.spriteTextures, img)
+	}
+}
+
+// drawSprites draws the sprites in z-order.
+func (dr *Driver) drawSprites() {
+	for _, sp := range dr.sprites {
+		if sp.Image == nil {
+			continue
+		}
+		tx, ok := dr.spriteTextures[sp.Image]
+		if !ok {
+			var err error
+			tx, err = dr.renderer.createTexture(sp.Image)
+			if err == nil {
+				err = tx.SetBlendMode(sdl.BLENDMODE_BLEND)
+			}
+			if err != nil {
+				dr.logf("sprite: %v", err)
+				continue
+			}
+			if dr.spriteTextures == nil {
+				dr.spriteTextures = make(map[image.Image]texture)
+			}
+			dr.spriteTextures[sp.Image] = tx
+		}
+		b := sp.Image.Bounds()
+		rect := sdl.Rect{X: int32(sp.P.X), Y: int32(sp.P.Y), W: int32(b.Dx()), H: int32(b.Dy())}
+		err := dr.renderer.copy(tx, nil, &rect)
+		if err != nil {
+			dr.logf("sprite: %v", err)
+		}
+	}
+}
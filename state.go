@@ -0,0 +1,162 @@
+package sdl
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// State contains the presentation settings of the driver that can be saved
+// with SaveState and restored with LoadState. The palette is given by the
+// TileManager, so palette settings are the color adjustments made by the
+// driver: color filter, tint and brightness. The driver does not play audio,
+// but it keeps audio volumes set with SetVolume, so that they can be
+// persisted along the other settings.
+type State struct {
+	Position    *image.Point       `json:",omitempty"` // window position in screen pixels
+	Size        *image.Point       `json:",omitempty"` // window size in screen pixels, for resizable windows
+	ScaleX      float32            // horizontal rendering scale
+	ScaleY      float32            // vertical rendering scale
+	Fullscreen  bool               // fullscreen mode
+	Opacity     float32            // window opacity
+	VSync       bool               // vertical synchronization
+	ColorFilter ColorFilter        // color filter applied to tiles
+	Tint        color.RGBA         // tint color and opacity (see SetTint)
+	Brightness  float32            // brightness factor (see SetBrightness)
+	Volumes     map[string]float64 `json:",omitempty"` // audio volumes by name
+}
+
+// State returns the current presentation settings. It should be called from
+// the main routine.
+func (dr *Driver) State() State {
+	st := State{
+		ScaleX:     dr.scaleX,
+		ScaleY:     dr.scaleY,
+		Fullscreen: dr.fullscreen,
+		Opacity:    dr.opacity,
+		VSync:      dr.vsync,
+
+		ColorFilter: dr.colorFilter,
+		Tint:        dr.tint,
+		Brightness:  dr.brightness,
+	}
+	if len(dr.volumes) > 0 {
+		st.Volumes = make(map[string]float64, len(dr.volumes))
+		for name, v := range dr.volumes {
+			st.Volumes[name] = v
+		}
+	}
+	if dr.init && !dr.fullscreen {
+		x, y := dr.window.GetPosition()
+		st.Position = &image.Point{X: int(x), Y: int(y)}
+	} else if dr.position != nil {
+		p := *dr.position
+		st.Position = &p
+	}
+	if !dr.resizable {
+		return st
+	}
+	if dr.init && !dr.fullscreen {
+		w, h := dr.window.GetSize()
+		st.Size = &image.Point{X: int(w), Y: int(h)}
+	} else if dr.size != nil {
+		p := *dr.size
+		st.Size = &p
+	}
+	return st
+}
+
+// SetState restores presentation settings. If the driver is already
+// running, changes will take effect with next Flush so that the function is
+// thread safe.
+func (dr *Driver) SetState(st State) {
+	if st.ScaleX > 0.1 && st.ScaleY > 0.1 {
+		dr.SetScale(st.ScaleX, st.ScaleY)
+	}
+	dr.SetFullscreen(st.Fullscreen)
+	if st.Opacity > 0 {
+		dr.SetOpacity(st.Opacity)
+	}
+	dr.SetVSync(st.VSync)
+	if st.ColorFilter != dr.colorFilter {
+		// Changing the filter clears the cache.
+		dr.SetColorFilter(st.ColorFilter)
+	}
+	dr.SetTint(st.Tint, st.Tint.A)
+	if st.Brightness > 0 {
+		dr.SetBrightness(st.Brightness)
+	}
+	for name, v := range st.Volumes {
+		dr.SetVolume(name, v)
+	}
+	if st.Position != nil {
+		p := *st.Position
+		fn := func() {
+			dr.window.SetPosition(int32(p.X), int32(p.Y))
+		}
+		dr.position = &p
+		if dr.init {
+			dr.queueSet("SetPosition", fn)
+		}
+	}
+	if st.Size != nil && dr.resizable {
+		p := *st.Size
+		fn := func() {
+			dr.window.SetSize(int32(p.X), int32(p.Y))
+			// Ensure the grid still fits.
+			dr.resizeWindow()
+		}
+		dr.size = &p
+		if dr.init {
+			dr.queueSet("SetSize", fn)
+		}
+	}
+}
+
+// SetVolume sets the audio volume with the given name, such as "music" or
+// "effects", so that it is saved in the driver's state (see State). The
+// driver does not play audio itself. It should be called from the main
+// routine.
+func (dr *Driver) SetVolume(name string, v float64) {
+	if dr.volumes == nil {
+		dr.volumes = make(map[string]float64)
+	}
+	dr.volumes[name] = v
+}
+
+// Volume returns the audio volume with the given name, as set by SetVolume or
+// restored by LoadState, and whether it was set. It should be called from the
+// main routine.
+func (dr *Driver) Volume(name string) (float64, bool) {
+	v, ok := dr.volumes[name]
+	return v, ok
+}
+
+// SaveState writes the current presentation settings (see State) as JSON, so
+// that applications can persist them with a single call. It should be called
+// from the main routine, for example before Close.
+func (dr *Driver) SaveState(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	err := enc.Encode(dr.State())
+	if err != nil {
+		return fmt.Errorf("save state: %v", err)
+	}
+	return nil
+}
+
+// LoadState reads presentation settings written by SaveState and restores
+// them as with SetState. It can be called before Init, so that the window is
+// created with the restored settings.
+func (dr *Driver) LoadState(r io.Reader) error {
+	// Start from current settings, so that missing fields are kept.
+	st := dr.State()
+	err := json.NewDecoder(r).Decode(&st)
+	if err != nil {
+		return fmt.Errorf("load state: %v", err)
+	}
+	dr.SetState(st)
+	return nil
+}
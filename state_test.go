@@ -0,0 +1,99 @@
+package sdl
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{Resizable: true})
+	dr.SetScale(2, 2)
+	dr.SetOpacity(0.5)
+	dr.SetVSync(true)
+	dr.SetColorFilter(ColorFilter{Mode: Deuteranopia, Correct: true})
+	dr.SetTint(color.RGBA{R: 0x20, G: 0x10, B: 0x80, A: 0xff}, 0x40)
+	dr.SetBrightness(0.75)
+	dr.SetVolume("music", 0.5)
+	dr.SetVolume("effects", 0.8)
+	dr.window.SetPosition(30, 40)
+	dr.window.SetSize(200, 100)
+	dr.Flush(testFrame(10, 5, "a"))
+	want := State{
+		Position:    &image.Point{X: 30, Y: 40},
+		Size:        &image.Point{X: 200, Y: 100},
+		ScaleX:      2,
+		ScaleY:      2,
+		Opacity:     0.5,
+		VSync:       true,
+		ColorFilter: ColorFilter{Mode: Deuteranopia, Correct: true},
+		Tint:        color.RGBA{R: 0x20, G: 0x10, B: 0x80, A: 0x40},
+		Brightness:  0.75,
+		Volumes:     map[string]float64{"music": 0.5, "effects": 0.8},
+	}
+	if st := dr.State(); !reflect.DeepEqual(st, want) {
+		t.Fatalf("state = %+v, want %+v", st, want)
+	}
+	var buf bytes.Buffer
+	if err := dr.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dr2, _ := newTestDriver(t, Config{Resizable: true})
+	if err := dr2.LoadState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := dr2.Volume("music"); !ok || v != 0.5 {
+		t.Errorf("music volume = %v, %v, want 0.5", v, ok)
+	}
+	dr2.Flush(testFrame(10, 5, "a"))
+	if st := dr2.State(); !reflect.DeepEqual(st, want) {
+		t.Errorf("loaded state = %+v, want %+v", st, want)
+	}
+}
+
+func TestLoadStateKeepsMissing(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	dr.SetBrightness(0.5)
+	dr.SetVolume("music", 0.3)
+	dr.Flush(testFrame(10, 5, "a"))
+	if err := dr.LoadState(strings.NewReader(`{"ScaleX": 2, "ScaleY": 2}`)); err != nil {
+		t.Fatal(err)
+	}
+	dr.Flush(testFrame(10, 5, "a"))
+	st := dr.State()
+	if st.ScaleX != 2 || st.Brightness != 0.5 || st.Volumes["music"] != 0.3 {
+		t.Errorf("bad state: %+v", st)
+	}
+	if err := dr.LoadState(strings.NewReader(`{`)); err == nil {
+		t.Error("no error for invalid state")
+	}
+}
+
+func TestSetStateCoalesces(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{Resizable: true})
+	for i := 0; i < 5; i++ {
+		dr.SetState(State{
+			Position: &image.Point{X: i, Y: i},
+			Size:     &image.Point{X: 100 + i, Y: 100 + i},
+		})
+	}
+	n := 0
+	for _, a := range dr.actions {
+		if a.key == "SetPosition" || a.key == "SetSize" {
+			n++
+		}
+	}
+	if n != 2 {
+		t.Errorf("%d position and size actions queued, want 2", n)
+	}
+	dr.Flush(testFrame(10, 5, "a"))
+	if x, y := dr.window.GetPosition(); x != 4 || y != 4 {
+		t.Errorf("position = (%d, %d), want (4, 4)", x, y)
+	}
+	if w, h := dr.window.GetSize(); w != 104 || h != 104 {
+		t.Errorf("size = %dx%d, want 104x104", w, h)
+	}
+}
@@ -0,0 +1,165 @@
+package sdl
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// Stats contains statistics about the driver, as returned by Driver.Stats.
+type Stats struct {
+	Frames           int64         // number of flushed frames
+	LastFrameTime    time.Duration // duration of last Flush
+	AvgFrameTime     time.Duration // average duration of Flush
+	Events           int64         // number of processed SDL events
+	Msgs             int64         // number of reported input messages
+	CoalescedActions int64         // number of queued actions replaced by later ones
+	CacheEntries     int           // number of cached tile textures
+	Stalls           int64         // number of frames exceeding the frame budget
+	Skipped          int64         // number of frames skipped as unchanged
+}
+
+// stats keeps track of driver statistics. It may be read concurrently, for
+// example by expvar's HTTP handler.
+type stats struct {
+	mu    sync.Mutex
+	st    Stats
+	total time.Duration // total time spent in Flush
+	last  time.Time     // time of last frame
+
+	samples []frameSample // recent frame timings
+	next    int           // next sample index once full
+
+	cache CacheStats
+}
+
+// CacheStats contains statistics about the tile texture cache, as returned
+// by Driver.CacheStats.
+type CacheStats struct {
+	Hits      int64 // number of tiles drawn using an already cached texture
+	Misses    int64 // number of textures created for missing tiles
+	Evictions int64 // number of textures evicted by cache limits
+	Entries   int   // number of cached textures
+	Memory    int   // estimated texture memory, in bytes
+}
+
+func (s *stats) addFrame(d time.Duration, entries int) {
+	s.mu.Lock()
+	s.st.Frames++
+	s.st.LastFrameTime = d
+	s.total += d
+	s.st.AvgFrameTime = s.total / time.Duration(s.st.Frames)
+	s.st.CacheEntries = entries
+	s.last = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *stats) addEvent() {
+	s.mu.Lock()
+	s.st.Events++
+	s.mu.Unlock()
+}
+
+func (s *stats) addMsg() {
+	s.mu.Lock()
+	s.st.Msgs++
+	s.mu.Unlock()
+}
+
+func (s *stats) coalesceAction() {
+	s.mu.Lock()
+	s.st.CoalescedActions++
+	s.mu.Unlock()
+}
+
+func (s *stats) addStall() {
+	s.mu.Lock()
+	s.st.Stalls++
+	s.mu.Unlock()
+}
+
+func (s *stats) addSkipped() {
+	s.mu.Lock()
+	s.st.Skipped++
+	s.mu.Unlock()
+}
+
+func (s *stats) setCache(cs CacheStats) {
+	s.mu.Lock()
+	s.cache = cs
+	s.st.CacheEntries = cs.Entries
+	s.mu.Unlock()
+}
+
+func (s *stats) lastFrame() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+func (s *stats) get() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.st
+}
+
+// Stats returns current driver statistics. It is safe to call it
+// concurrently, so that statistics can be exported to any kind of metrics
+// system.
+func (dr *Driver) Stats() Stats {
+	return dr.stats.get()
+}
+
+// CacheStats returns current texture cache statistics, as of the last Flush
+// or cache clear. It can help tuning a TileManager or cache limits, and
+// detecting unexpected cache growth, for example because of attributes or
+// colors that should be ignored with Config.CacheKey. It is safe to call it
+// concurrently.
+func (dr *Driver) CacheStats() CacheStats {
+	dr.stats.mu.Lock()
+	defer dr.stats.mu.Unlock()
+	return dr.stats.cache
+}
+
+// PublishExpvar publishes the driver statistics as an expvar variable with
+// the given name. As with expvar.Publish, it panics if the name is already in
+// use. See RegisterMetrics for other metrics systems.
+func (dr *Driver) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return dr.Stats()
+	}))
+}
+
+// RegisterMetrics registers the driver statistics with a metrics system, by
+// calling register for each statistic, with a name in snake case and a
+// function returning its current value. Durations are in seconds, and
+// memory in bytes. It is meant as an adapter for metrics systems other than
+// expvar, for example for Prometheus:
+//
+//	dr.RegisterMetrics(func(name string, value func() float64) {
+//		prometheus.MustRegister(prometheus.NewGaugeFunc(
+//			prometheus.GaugeOpts{Name: "gruid_sdl_" + name}, value))
+//	})
+//
+// The value functions are safe to call concurrently.
+func (dr *Driver) RegisterMetrics(register func(name string, value func() float64)) {
+	stat := func(f func(Stats) float64) func() float64 {
+		return func() float64 { return f(dr.Stats()) }
+	}
+	cache := func(f func(CacheStats) float64) func() float64 {
+		return func() float64 { return f(dr.CacheStats()) }
+	}
+	register("frames", stat(func(st Stats) float64 { return float64(st.Frames) }))
+	register("last_frame_seconds", stat(func(st Stats) float64 { return st.LastFrameTime.Seconds() }))
+	register("avg_frame_seconds", stat(func(st Stats) float64 { return st.AvgFrameTime.Seconds() }))
+	register("events", stat(func(st Stats) float64 { return float64(st.Events) }))
+	register("msgs", stat(func(st Stats) float64 { return float64(st.Msgs) }))
+	register("coalesced_actions", stat(func(st Stats) float64 { return float64(st.CoalescedActions) }))
+	register("stalls", stat(func(st Stats) float64 { return float64(st.Stalls) }))
+	register("skipped_frames", stat(func(st Stats) float64 { return float64(st.Skipped) }))
+	register("cache_hits", cache(func(cs CacheStats) float64 { return float64(cs.Hits) }))
+	register("cache_misses", cache(func(cs CacheStats) float64 { return float64(cs.Misses) }))
+	register("cache_evictions", cache(func(cs CacheStats) float64 { return float64(cs.Evictions) }))
+	register("cache_entries", cache(func(cs CacheStats) float64 { return float64(cs.Entries) }))
+	register("cache_memory_bytes", cache(func(cs CacheStats) float64 { return float64(cs.Memory) }))
+}
@@ -0,0 +1,39 @@
+package sdl
+
+import (
+	"testing"
+
+	"github.com/anaseto/gruid"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	metrics := map[string]func() float64{}
+	dr.RegisterMetrics(func(name string, value func() float64) {
+		if _, ok := metrics[name]; ok {
+			t.Errorf("metric %s registered twice", name)
+		}
+		metrics[name] = value
+	})
+	for _, name := range []string{"frames", "skipped_frames", "cache_misses", "cache_entries"} {
+		if metrics[name] == nil {
+			t.Fatalf("metric %s not registered", name)
+		}
+	}
+	dr.Flush(testFrame(10, 5, "ab"))
+	dr.Flush(gruid.Frame{Width: 10, Height: 5})
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{"frames", 1},
+		{"skipped_frames", 1},
+		{"cache_misses", 2},
+		{"cache_entries", 2},
+	}
+	for _, tt := range tests {
+		if got := metrics[tt.name](); got != tt.want {
+			t.Errorf("metric %s = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
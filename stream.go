@@ -0,0 +1,184 @@
+package sdl
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/anaseto/gruid"
+)
+
+// streamBuffer is the number of frames buffered for each stream client.
+// Clients that fall behind more than that are resynchronized with a full
+// frame.
+const streamBuffer = 64
+
+// stream represents a running frame streaming server.
+type stream struct {
+	ln      net.Listener
+	mu      sync.Mutex
+	clients map[*streamClient]bool
+}
+
+// streamClient represents a connection to a spectator.
+type streamClient struct {
+	conn   net.Conn
+	frames chan gruid.Frame
+	sync   bool // whether the client needs a full frame
+}
+
+// StartStream starts a server listening on the given TCP address, such as
+// "localhost:7777", for streaming flushed frames to spectators. The stream
+// uses the same format as gruid's frame recordings: each client receives a
+// gzip compressed sequence of gob encoded gruid.Frame values, starting with a
+// full frame, and followed by cell deltas. It can be read with a
+// gruid.FrameDecoder, for example to mirror the session with another driver.
+// It returns the address the server listens on.
+//
+// The stream is neither authenticated nor encrypted: anyone who can connect
+// to the address gets the whole session content. For this reason, an
+// address without host, such as ":7777", listens only on the loopback
+// interface (127.0.0.1). Listening on other interfaces requires an explicit
+// host, such as "0.0.0.0:7777", and should be restricted by other means,
+// like a firewall or an SSH tunnel.
+func (dr *Driver) StartStream(addr string) (net.Addr, error) {
+	if dr.stream != nil {
+		return nil, fmt.Errorf("stream: already started")
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("stream: %v", err)
+	}
+	if host == "" {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("stream: %v", err)
+	}
+	st := &stream{ln: ln, clients: make(map[*streamClient]bool)}
+	dr.stream = st
+	go dr.acceptStream(st)
+	return ln.Addr(), nil
+}
+
+// StopStream stops the frame streaming server and disconnects all clients.
+// It is called automatically by Close.
+func (dr *Driver) StopStream() error {
+	st := dr.stream
+	if st == nil {
+		return nil
+	}
+	dr.stream = nil
+	err := st.ln.Close()
+	st.mu.Lock()
+	for cl := range st.clients {
+		close(cl.frames)
+		delete(st.clients, cl)
+	}
+	st.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("stream: %v", err)
+	}
+	return nil
+}
+
+func (dr *Driver) acceptStream(st *stream) {
+	for {
+		conn, err := st.ln.Accept()
+		if err != nil {
+			return
+		}
+		cl := &streamClient{
+			conn:   conn,
+			frames: make(chan gruid.Frame, streamBuffer),
+			sync:   true,
+		}
+		st.mu.Lock()
+		st.clients[cl] = true
+		st.mu.Unlock()
+		go dr.serveStream(st, cl)
+	}
+}
+
+func (dr *Driver) serveStream(st *stream, cl *streamClient) {
+	defer cl.conn.Close()
+	gzw := gzip.NewWriter(cl.conn)
+	enc := gob.NewEncoder(gzw)
+	for frame := range cl.frames {
+		err := enc.Encode(frame)
+		if err == nil {
+			err = gzw.Flush()
+		}
+		if err != nil {
+			dr.logf("stream: %s: %v", cl.conn.RemoteAddr(), err)
+			st.mu.Lock()
+			if st.clients[cl] {
+				delete(st.clients, cl)
+				close(cl.frames)
+			}
+			st.mu.Unlock()
+			// drain remaining frames
+			for range cl.frames {
+			}
+			return
+		}
+	}
+	gzw.Close()
+}
+
+// streamFrame sends a flushed frame to stream clients, if any.
+func (dr *Driver) streamFrame(frame gruid.Frame) {
+	st := dr.stream
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if len(st.clients) == 0 {
+		return
+	}
+	var delta gruid.Frame
+	if len(frame.Cells) > 0 {
+		// The frame's cells may be reused by the application, so we
+		// have to copy them.
+		delta = frame
+		delta.Cells = append([]gruid.FrameCell(nil), frame.Cells...)
+	}
+	var full gruid.Frame
+	for cl := range st.clients {
+		if cl.sync {
+			if full.Cells == nil {
+				full = dr.fullFrame(frame)
+			}
+			select {
+			case cl.frames <- full:
+				cl.sync = false
+			default:
+			}
+			continue
+		}
+		if delta.Cells == nil {
+			continue
+		}
+		select {
+		case cl.frames <- delta:
+		default:
+			// Client is too slow: resynchronize later.
+			cl.sync = true
+		}
+	}
+}
+
+// fullFrame returns a frame with the whole current grid content.
+func (dr *Driver) fullFrame(frame gruid.Frame) gruid.Frame {
+	w := int(dr.width)
+	full := gruid.Frame{Time: frame.Time, Width: frame.Width, Height: frame.Height}
+	full.Cells = make([]gruid.FrameCell, 0, len(dr.grid))
+	for i, c := range dr.grid {
+		full.Cells = append(full.Cells, gruid.FrameCell{Cell: c, P: gruid.Point{X: i % w, Y: i / w}})
+	}
+	return full
+}
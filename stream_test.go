@@ -0,0 +1,76 @@
+package sdl
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/anaseto/gruid"
+)
+
+func TestStream(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	addr, err := dr.StartStream(":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip := addr.(*net.TCPAddr).IP; !ip.IsLoopback() {
+		t.Errorf("listening on %v, want loopback", ip)
+	}
+	if _, err := dr.StartStream(":0"); err == nil {
+		t.Error("no error when starting a second stream")
+	}
+	dr.Flush(testFrame(10, 5, "ab"))
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// Wait for the client to be registered.
+	for i := 0; ; i++ {
+		dr.stream.mu.Lock()
+		n := len(dr.stream.clients)
+		dr.stream.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if i > 100 {
+			t.Fatal("client not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// New clients get a full frame on next Flush, even if nothing
+	// changed.
+	dr.Flush(gruid.Frame{Width: 10, Height: 5})
+	dr.Flush(testFrame(10, 5, "", "c"))
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	// gruid.FrameDecoder retries on errors, so a gob decoder is used
+	// directly, in order to detect timeouts.
+	gzr, err := gzip.NewReader(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd := gob.NewDecoder(gzr)
+	var full, delta gruid.Frame
+	if err := fd.Decode(&full); err != nil {
+		t.Fatal(err)
+	}
+	if len(full.Cells) != 50 || full.Cells[1].Cell.Rune != 'b' {
+		t.Errorf("bad full frame: %d cells, second %v", len(full.Cells), full.Cells[1])
+	}
+	if err := fd.Decode(&delta); err != nil {
+		t.Fatal(err)
+	}
+	want := gruid.FrameCell{P: gruid.Point{X: 0, Y: 1}, Cell: gruid.Cell{Rune: 'c'}}
+	if len(delta.Cells) != 1 || delta.Cells[0] != want {
+		t.Errorf("delta cells = %v, want %v", delta.Cells, want)
+	}
+	if err := dr.StopStream(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fd.Decode(&delta); err == nil {
+		t.Error("stream not closed")
+	}
+}
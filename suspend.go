@@ -0,0 +1,40 @@
+package sdl
+
+import (
+	"github.com/anaseto/gruid"
+)
+
+// suspend stops rendering, as the window is not visible. Flushed frames are
+// then only recorded, and the polling interval of PollMsgs, if used instead of
+// gruid.App, is lengthened.
+func (dr *Driver) suspend() {
+	dr.suspended = true
+}
+
+// resume resumes rendering, if suspended, with a full redraw of the grid. It
+// reports whether rendering was suspended.
+func (dr *Driver) resume() bool {
+	if !dr.suspended {
+		return false
+	}
+	dr.suspended = false
+	dr.fullRedraw = true
+	dr.needRefresh = true
+	return true
+}
+
+// storeFrame records the frame's cells into the grid, without drawing them,
+// while rendering is suspended.
+func (dr *Driver) storeFrame(frame gruid.Frame) {
+	w, h := int(dr.width), int(dr.height)
+	if len(dr.grid) != w*h {
+		dr.grid = make([]gruid.Cell, w*h)
+	}
+	for _, fc := range frame.Cells {
+		if fc.P.X >= 0 && fc.P.X < w && fc.P.Y >= 0 && fc.P.Y < h {
+			dr.grid[fc.P.X+w*fc.P.Y] = fc.Cell
+		}
+	}
+	// The window no longer matches the grid.
+	dr.drawn = false
+}
@@ -0,0 +1,99 @@
+package sdl
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// maxPooledTextures is the maximum number of released streaming textures
+// kept for reuse, per tile size.
+const maxPooledTextures = 256
+
+// streamTexture returns a streaming tile texture for an image, reusing a
+// released texture of the same size if possible.
+func (dr *Driver) streamTexture(img *image.NRGBA, opaque bool) (texture, error) {
+	size := img.Rect.Size()
+	var tx texture
+	if pool := dr.streamPool[size]; len(pool) > 0 {
+		tx = pool[len(pool)-1]
+		pool[len(pool)-1] = nil
+		dr.streamPool[size] = pool[:len(pool)-1]
+	} else {
+		var err error
+		tx, err = dr.renderer.createStreaming(int32(size.X), int32(size.Y))
+		if err != nil {
+			return nil, fmt.Errorf("streaming texture: %v", err)
+		}
+		if dr.streamSizes == nil {
+			dr.streamSizes = map[texture]image.Point{}
+		}
+		dr.streamSizes[tx] = size
+	}
+	err := dr.resetStreaming(tx, img, opaque)
+	if err != nil {
+		dr.destroyTexture(tx)
+		return nil, fmt.Errorf("streaming texture: %v", err)
+	}
+	return tx, nil
+}
+
+// resetStreaming updates the pixels of a streaming texture, and restores the
+// modes that might have been changed while used for another tile.
+func (dr *Driver) resetStreaming(tx texture, img *image.NRGBA, opaque bool) error {
+	err := dr.renderer.updateTexture(tx, sdl.Rect{W: int32(img.Rect.Dx()), H: int32(img.Rect.Dy())}, img)
+	if err != nil {
+		return err
+	}
+	var bm sdl.BlendMode = sdl.BLENDMODE_BLEND
+	if opaque {
+		bm = sdl.BLENDMODE_NONE
+	}
+	if err := tx.SetBlendMode(bm); err != nil {
+		return err
+	}
+	if err := tx.SetAlphaMod(255); err != nil {
+		return err
+	}
+	return tx.SetColorMod(255, 255, 255)
+}
+
+// releaseTexture releases the texture of an evicted tile, keeping it for
+// reuse if it is a streaming texture.
+func (dr *Driver) releaseTexture(tx texture) {
+	size, ok := dr.streamSizes[tx]
+	if !ok || len(dr.streamPool[size]) >= maxPooledTextures {
+		dr.destroyTexture(tx)
+		return
+	}
+	if dr.streamPool == nil {
+		dr.streamPool = map[image.Point][]texture{}
+	}
+	dr.streamPool[size] = append(dr.streamPool[size], tx)
+}
+
+// destroyTexture destroys a tile texture, forgetting it if it was a
+// streaming texture.
+func (dr *Driver) destroyTexture(tx texture) {
+	delete(dr.streamSizes, tx)
+	err := tx.Destroy()
+	if err != nil {
+		dr.logf("texture destroy: %v", err)
+	}
+}
+
+// clearStreamPool destroys released streaming textures. It should be called
+// after destroying cached textures.
+func (dr *Driver) clearStreamPool() {
+	for _, pool := range dr.streamPool {
+		for _, tx := range pool {
+			err := tx.Destroy()
+			if err != nil {
+				dr.logf("texture destroy: %v", err)
+			}
+		}
+	}
+	dr.streamPool = nil
+	dr.streamSizes = nil
+}
@@ -0,0 +1,84 @@
+package sdl
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// MsgTick is reported at a fixed rate when the TickRate configuration option
+// is set. Ticks are not queued: if the application does not handle a tick
+// before the next one is due, the latter is skipped and counted in the next
+// message's Missed field.
+type MsgTick struct {
+	Missed int       // number of skipped ticks since the previous message
+	Time   time.Time // time when the message was generated
+}
+
+// ticker generates tick events from a separate goroutine.
+type ticker struct {
+	typ     uint32 // registered SDL event type
+	stop    chan struct{}
+	pending int32 // whether a tick event is waiting (atomic)
+	missed  int32 // number of skipped ticks (atomic)
+}
+
+// startTicker starts generating tick events, if configured. Events are
+// pushed into the SDL event queue, which is thread safe, so that they wake
+// up WaitMsg and PollMsgs as any other event.
+func (dr *Driver) startTicker() {
+	if dr.tickRate <= 0 || dr.ticker != nil {
+		return
+	}
+	typ, err := dr.backend.registerEvent()
+	if err != nil {
+		dr.logf("ticker: %v", err)
+		return
+	}
+	tk := &ticker{typ: typ, stop: make(chan struct{})}
+	dr.ticker = tk
+	interval := time.Duration(float64(time.Second) / dr.tickRate)
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-tk.stop:
+				return
+			case <-t.C:
+			}
+			if !atomic.CompareAndSwapInt32(&tk.pending, 0, 1) {
+				atomic.AddInt32(&tk.missed, 1)
+				continue
+			}
+			err := dr.backend.pushEvent(&sdl.UserEvent{Type: tk.typ})
+			if err != nil {
+				atomic.StoreInt32(&tk.pending, 0)
+				atomic.AddInt32(&tk.missed, 1)
+			}
+		}
+	}()
+}
+
+// stopTicker stops generating tick events.
+func (dr *Driver) stopTicker() {
+	if dr.ticker == nil {
+		return
+	}
+	close(dr.ticker.stop)
+	dr.ticker = nil
+}
+
+// pollUserEvent translates tick events into MsgTick messages.
+func (dr *Driver) pollUserEvent(ev *sdl.UserEvent) *MsgTick {
+	tk := dr.ticker
+	if tk == nil || ev.Type != tk.typ {
+		return nil
+	}
+	atomic.StoreInt32(&tk.pending, 0)
+	return &MsgTick{Missed: int(atomic.SwapInt32(&tk.missed, 0)), Time: time.Now()}
+}
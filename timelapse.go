@@ -0,0 +1,165 @@
+package sdl
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxTimelapseFrames is the maximum number of frames kept in memory for an
+// animated timelapse. Beyond that, every other frame is dropped and the
+// capture rate is halved.
+const maxTimelapseFrames = 512
+
+// Timelapse contains configuration options for timelapse capture.
+type Timelapse struct {
+	// Dir is the directory into which captured frames are saved. It is
+	// created if necessary. An empty directory disables timelapse
+	// capture.
+	Dir string
+
+	// Interval is the minimum time between two captured frames (default:
+	// 1s).
+	Interval time.Duration
+
+	// Flushes, if positive, makes the driver capture one frame every
+	// given number of flushes, instead of using Interval.
+	Flushes int
+
+	// GIF makes the driver gather captured frames into a single animated
+	// GIF file when the timelapse is stopped, instead of saving each
+	// frame as a PNG file.
+	GIF bool
+
+	// FrameDelay is the delay between frames in the animated GIF
+	// (default: 100ms).
+	FrameDelay time.Duration
+}
+
+// timelapse represents the state of a running timelapse capture.
+type timelapse struct {
+	Timelapse
+	name    string // base name for files
+	last    time.Time
+	flushes int
+	step    int // capture one frame out of step
+	count   int // number of captured frames
+	frames  []*image.Paletted
+}
+
+// StartTimelapse starts timelapse capture with the given options, stopping
+// any previous one. It should be called from the main routine. Timelapse
+// capture can also be started automatically with Config.Timelapse.
+func (dr *Driver) StartTimelapse(tl Timelapse) error {
+	if dr.timelapse != nil {
+		if _, err := dr.StopTimelapse(); err != nil {
+			dr.logf("timelapse: %v", err)
+		}
+	}
+	if tl.Dir == "" {
+		return fmt.Errorf("timelapse: no directory")
+	}
+	if err := os.MkdirAll(tl.Dir, 0755); err != nil {
+		return fmt.Errorf("timelapse: %v", err)
+	}
+	if tl.Interval <= 0 {
+		tl.Interval = time.Second
+	}
+	if tl.FrameDelay <= 0 {
+		tl.FrameDelay = 100 * time.Millisecond
+	}
+	dr.timelapse = &timelapse{
+		Timelapse: tl,
+		name:      "timelapse-" + time.Now().Format("20060102-150405"),
+		step:      1,
+	}
+	return nil
+}
+
+// StopTimelapse stops timelapse capture. When capturing an animated GIF, the
+// file is written and its path returned. It should be called from the main
+// routine. It is called automatically by Close.
+func (dr *Driver) StopTimelapse() (string, error) {
+	tl := dr.timelapse
+	if tl == nil {
+		return "", nil
+	}
+	dr.timelapse = nil
+	if !tl.GIF || len(tl.frames) == 0 {
+		return "", nil
+	}
+	anim := &gif.GIF{}
+	delay := int(tl.FrameDelay / (10 * time.Millisecond))
+	for _, img := range tl.frames {
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, delay)
+	}
+	path := filepath.Join(tl.Dir, tl.name+".gif")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("timelapse: %v", err)
+	}
+	err = gif.EncodeAll(f, anim)
+	if err != nil {
+		f.Close()
+		return "", fmt.Errorf("timelapse: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("timelapse: %v", err)
+	}
+	return path, nil
+}
+
+// captureTimelapse captures current window content if a timelapse is running
+// and a new frame is due, given the time at which the current frame was
+// presented.
+func (dr *Driver) captureTimelapse(t time.Time) {
+	tl := dr.timelapse
+	if tl == nil {
+		return
+	}
+	tl.flushes++
+	if tl.Flushes > 0 {
+		if tl.flushes < tl.Flushes*tl.step {
+			return
+		}
+	} else if !tl.last.IsZero() && t.Sub(tl.last) < tl.Interval*time.Duration(tl.step) {
+		return
+	}
+	tl.flushes = 0
+	tl.last = t
+	img, err := dr.Screenshot()
+	if err != nil {
+		dr.logf("timelapse: %v", err)
+		return
+	}
+	tl.count++
+	if !tl.GIF {
+		path := filepath.Join(tl.Dir, fmt.Sprintf("%s-%05d.png", tl.name, tl.count))
+		if err := writePNG(path, img); err != nil {
+			dr.logf("timelapse: %v", err)
+		}
+		return
+	}
+	pimg := image.NewPaletted(img.Bounds(), palette.Plan9)
+	draw.Draw(pimg, pimg.Rect, img, img.Bounds().Min, draw.Src)
+	tl.frames = append(tl.frames, pimg)
+	if len(tl.frames) >= maxTimelapseFrames {
+		// Keep memory bounded by halving the capture rate.
+		n := 0
+		for i := 0; i < len(tl.frames); i += 2 {
+			tl.frames[n] = tl.frames[i]
+			n++
+		}
+		for i := n; i < len(tl.frames); i++ {
+			tl.frames[i] = nil
+		}
+		tl.frames = tl.frames[:n]
+		tl.step *= 2
+	}
+}
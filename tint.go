@@ -0,0 +1,96 @@
+package sdl
+
+import (
+	"image/color"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// SetTint tints the whole rendered content with a color, blended with the
+// given opacity, as for a night mode. A zero alpha disables tinting. The tint
+// is drawn as a full-screen pass on top of the grid, so that the
+// TileManager's tiles are not affected. If the driver is already running,
+// change will take effect with next Flush so that the function is thread
+// safe.
+func (dr *Driver) SetTint(c color.Color, alpha uint8) {
+	r, g, b := rgb(c)
+	fn := func() {
+		dr.tint = color.RGBA{R: r, G: g, B: b, A: alpha}
+	}
+	if dr.init {
+		dr.queueSet("SetTint", fn)
+	} else {
+		fn()
+	}
+}
+
+// SetBrightness changes the brightness of the rendered content, as a factor
+// between 0 (black) and 2 (twice brighter) by which colors are multiplied.
+// The default is 1. As with SetTint, it is applied as a full-screen pass on
+// top of the grid. Brightening requires custom blend modes, which are not
+// supported by the software renderer: in that case, factors above 1 have no
+// effect. If the driver is already running, change will take effect with
+// next Flush so that the function is thread safe.
+func (dr *Driver) SetBrightness(f float32) {
+	if f < 0 {
+		f = 0
+	} else if f > 2 {
+		f = 2
+	}
+	fn := func() {
+		dr.brightness = f
+	}
+	if dr.init {
+		dr.queueSet("SetBrightness", fn)
+	} else {
+		fn()
+	}
+}
+
+// adjusted reports whether tint or brightness adjustments are enabled.
+func (dr *Driver) adjusted() bool {
+	return dr.tint.A > 0 || dr.brightness != 1
+}
+
+// drawAdjustments draws the tint and brightness passes.
+func (dr *Driver) drawAdjustments() {
+	r := dr.renderer
+	w, h := dr.windowSize()
+	rect := &sdl.Rect{W: w, H: h}
+	defer r.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+	if dr.tint.A > 0 {
+		r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+		r.SetDrawColor(dr.tint.R, dr.tint.G, dr.tint.B, dr.tint.A)
+		err := r.FillRect(rect)
+		if err != nil {
+			dr.logf("tint: %v", err)
+		}
+	}
+	switch {
+	case dr.brightness < 1:
+		r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+		r.SetDrawColor(0, 0, 0, uint8(255*(1-dr.brightness)))
+	case dr.brightness > 1:
+		v := uint8(255 * (dr.brightness - 1))
+		err := r.SetDrawBlendMode(brightenBlendMode())
+		if err != nil {
+			dr.logf("brightness: %v", err)
+			return
+		}
+		r.SetDrawColor(v, v, v, 255)
+	default:
+		return
+	}
+	err := r.FillRect(rect)
+	if err != nil {
+		dr.logf("brightness: %v", err)
+	}
+}
+
+// brightenBlendMode returns a blend mode that multiplies destination colors
+// by one plus the source color, so that brightening keeps hues and does not
+// turn dark colors into grey.
+func brightenBlendMode() sdl.BlendMode {
+	return sdl.ComposeCustomBlendMode(sdl.BLENDFACTOR_DST_COLOR, sdl.BLENDFACTOR_ONE, sdl.BLENDOPERATION_ADD,
+		sdl.BLENDFACTOR_ZERO, sdl.BLENDFACTOR_ONE, sdl.BLENDOPERATION_ADD)
+}
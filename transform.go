@@ -0,0 +1,54 @@
+package sdl
+
+import (
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// TileTransformer is an optional extension of TileManager for tile managers
+// that can reuse one image for several cells by flipping or rotating it, such
+// as for mirrored creatures or rotated walls. When implemented, the driver
+// draws the tile of each cell with the transformation returned by
+// GetTransform. Note that the texture cache is still indexed by cell, so a
+// Config.CacheKey function can be used so that transformed cells share the
+// same texture.
+type TileTransformer interface {
+	TileManager
+
+	// GetTransform returns whether the tile for a cell should be flipped
+	// horizontally or vertically, and its clockwise rotation angle, in
+	// degrees, around the cell's center. Flips are applied before
+	// rotation.
+	GetTransform(gruid.Cell) (flipH, flipV bool, angle float64)
+}
+
+// transform returns the transformation of the tile for a cell, and whether
+// it is not the identity.
+func (dr *Driver) transform(c gruid.Cell) (float64, sdl.RendererFlip, bool) {
+	tt, ok := dr.tm.(TileTransformer)
+	if !ok {
+		return 0, sdl.FLIP_NONE, false
+	}
+	c.Style.Attrs &^= dr.ghostAttr
+	flipH, flipV, angle := tt.GetTransform(c)
+	flip := sdl.FLIP_NONE
+	if flipH {
+		flip |= sdl.FLIP_HORIZONTAL
+	}
+	if flipV {
+		flip |= sdl.FLIP_VERTICAL
+	}
+	return angle, flip, flip != sdl.FLIP_NONE || angle != 0
+}
+
+// copyTextureEx copies a tile texture to the current rendering target,
+// flipped and rotated.
+func (dr *Driver) copyTextureEx(tx texture, dst *sdl.Rect, angle float64, flip sdl.RendererFlip) error {
+	if at, ok := tx.(*atlasTile); ok {
+		if err := at.apply(); err != nil {
+			return err
+		}
+		return dr.renderer.copyEx(at.page.tx, &at.rect, dst, angle, flip)
+	}
+	return dr.renderer.copyEx(tx, nil, dst, angle, flip)
+}
@@ -0,0 +1,192 @@
+package sdl
+
+import (
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// transitionKind describes a kind of transition between frames.
+type transitionKind int
+
+const (
+	fadeOut transitionKind = iota + 1
+	fadeIn
+	crossFade
+)
+
+// transition describes an ongoing transition.
+type transition struct {
+	kind     transitionKind
+	start    time.Time
+	duration time.Duration
+	from     texture // previous content, for cross fades
+}
+
+// FadeOut progressively darkens the presented content to black over the
+// given duration. The window then stays black until FadeIn or CrossFade is
+// called. Transitions draw into an offscreen backbuffer, as with
+// Config.Backbuffer. If the driver is already running, change will take
+// effect with next Flush so that the function is thread safe.
+func (dr *Driver) FadeOut(duration time.Duration) {
+	dr.startTransition(fadeOut, duration)
+}
+
+// FadeIn progressively reveals the presented content from black over the
+// given duration. If the driver is already running, change will take effect
+// with next Flush so that the function is thread safe.
+func (dr *Driver) FadeIn(duration time.Duration) {
+	dr.startTransition(fadeIn, duration)
+}
+
+// CrossFade progressively blends the previously presented content into the
+// new frames over the given duration, as for scene changes. It should be
+// called just before flushing the first frame of the new scene. If the
+// driver is already running, change will take effect with next Flush so that
+// the function is thread safe.
+func (dr *Driver) CrossFade(duration time.Duration) {
+	dr.startTransition(crossFade, duration)
+}
+
+func (dr *Driver) startTransition(kind transitionKind, duration time.Duration) {
+	fn := func() {
+		dr.endTransition()
+		if duration <= 0 {
+			dr.faded = kind == fadeOut
+			dr.fullRedraw = true
+			dr.needRefresh = true
+			return
+		}
+		if !dr.usesBackbuffer() {
+			// The backbuffer is not up to date.
+			dr.fullRedraw = true
+			dr.needRefresh = true
+		}
+		tr := transition{kind: kind, start: time.Now(), duration: duration}
+		if kind == crossFade {
+			tr.from = dr.snapshotBackbuffer()
+			if tr.from == nil {
+				// No previous content: fade in from black.
+				tr.kind = fadeIn
+			}
+		}
+		if tr.kind != fadeOut {
+			dr.faded = false
+		}
+		dr.transition = tr
+	}
+	if dr.init {
+		dr.queue(fn)
+	} else {
+		fn()
+	}
+}
+
+// transitioning reports whether a transition is ongoing, or the content is
+// faded out.
+func (dr *Driver) transitioning() bool {
+	return dr.transition.kind != 0 || dr.faded
+}
+
+// snapshotBackbuffer returns a copy of the backbuffer, or nil if there is
+// no up to date backbuffer.
+func (dr *Driver) snapshotBackbuffer() texture {
+	if !dr.usesBackbuffer() || dr.bb == nil || !dr.drawn {
+		return nil
+	}
+	w, h := int32(dr.bbSize.X), int32(dr.bbSize.Y)
+	tx, err := dr.renderer.createTarget(w, h)
+	if err == nil {
+		err = dr.renderer.setTarget(tx)
+	}
+	if err == nil {
+		rect := sdl.Rect{W: w, H: h}
+		err = dr.renderer.copy(dr.bb, &rect, &rect)
+	}
+	if err == nil {
+		err = tx.SetBlendMode(sdl.BLENDMODE_BLEND)
+	}
+	if err != nil {
+		dr.logf("transition: %v", err)
+		if tx != nil {
+			tx.Destroy()
+		}
+		tx = nil
+	}
+	err = dr.renderer.setTarget(nil)
+	if err != nil {
+		dr.logf("transition: %v", err)
+	}
+	return tx
+}
+
+// endTransition ends the ongoing transition, if any, releasing its
+// resources.
+func (dr *Driver) endTransition() {
+	if dr.transition.from != nil {
+		err := dr.transition.from.Destroy()
+		if err != nil {
+			dr.logf("transition destroy: %v", err)
+		}
+	}
+	if dr.transition.kind == fadeOut {
+		dr.faded = true
+	}
+	dr.transition = transition{}
+}
+
+// checkTransition presents the content again during transitions, and ends
+// them when their duration elapsed.
+func (dr *Driver) checkTransition() {
+	if dr.transition.kind == 0 || dr.suspended {
+		return
+	}
+	elapsed := time.Since(dr.transition.start)
+	if elapsed >= dr.transition.duration {
+		dr.endTransition()
+		if !dr.represent() {
+			dr.fullRedraw = true
+			dr.needRefresh = true
+		}
+		return
+	}
+	if time.Since(dr.presented) >= effectInterval {
+		dr.represent()
+	}
+}
+
+// drawTransition draws the current transition state on top of the
+// presented content.
+func (dr *Driver) drawTransition(rect *sdl.Rect) {
+	var t float32 = 1
+	if tr := dr.transition; tr.kind != 0 {
+		t = float32(time.Since(tr.start)) / float32(tr.duration)
+		if t > 1 {
+			t = 1
+		}
+	}
+	var black float32
+	switch {
+	case dr.transition.kind == fadeOut:
+		black = t
+	case dr.transition.kind == fadeIn:
+		black = 1 - t
+	case dr.transition.kind == crossFade:
+		tx := dr.transition.from
+		tx.SetAlphaMod(uint8(255 * (1 - t)))
+		err := dr.renderer.copy(tx, dr.zoomRect(), rect)
+		if err != nil {
+			dr.logf("transition: %v", err)
+		}
+	case dr.faded:
+		black = 1
+	}
+	if black <= 0 {
+		return
+	}
+	r := dr.renderer
+	r.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	r.SetDrawColor(0, 0, 0, uint8(255*black))
+	r.FillRect(nil)
+	r.SetDrawBlendMode(sdl.BLENDMODE_NONE)
+}
@@ -0,0 +1,313 @@
+package sdl
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Viewport represents a secondary grid drawn into a pixel region of the
+// window, with its own TileManager and tile size, such as a sidebar or a log
+// pane using a different font than the main grid. Viewports are created with
+// Driver.NewViewport and flushed independently from the main grid.
+type Viewport struct {
+	dr       *Driver
+	rect     image.Rectangle
+	tm       TileManager
+	tw, th   int32
+	width    int
+	height   int
+	grid     []gruid.Cell
+	textures map[gruid.Cell]texture
+	mousepos gruid.Point
+	dirty    bool // whether the drawn content was lost
+}
+
+// MsgViewportMouse is reported instead of gruid.MsgMouse for mouse events
+// happening over a viewport. The message's position is relative to the
+// viewport's grid.
+type MsgViewportMouse struct {
+	Viewport *Viewport      // viewport hit by the mouse
+	Mouse    gruid.MsgMouse // mouse message in viewport cell coordinates
+}
+
+// NewViewport returns a new viewport drawing into the given pixel region of
+// the window, in unscaled window coordinates, using the given TileManager.
+// The viewport's grid size is the number of tiles fitting into the region.
+// The window is enlarged as necessary to contain the region. It should be
+// called from the main routine, after Init.
+func (dr *Driver) NewViewport(rect image.Rectangle, tm TileManager) *Viewport {
+	vp := &Viewport{dr: dr, rect: rect.Canon(), textures: make(map[gruid.Cell]texture)}
+	vp.SetTileManager(tm)
+	dr.viewports = append(dr.viewports, vp)
+	dr.resizeWindow()
+	return vp
+}
+
+// SetTileManager changes the viewport's TileManager. It clears the
+// viewport's grid, so that the next Flush should contain all the cells. It
+// should be called from the main routine.
+func (vp *Viewport) SetTileManager(tm TileManager) {
+	vp.tm = tm
+	p := tm.TileSize()
+	vp.tw, vp.th = int32(p.X), int32(p.Y)
+	if vp.tw <= 0 {
+		vp.tw = 1
+	}
+	if vp.th <= 0 {
+		vp.th = 1
+	}
+	vp.ClearCache()
+	vp.width = vp.rect.Dx() / int(vp.tw)
+	vp.height = vp.rect.Dy() / int(vp.th)
+	vp.grid = make([]gruid.Cell, vp.width*vp.height)
+	vp.mousepos = gruid.Point{X: -1, Y: -1}
+}
+
+// Size returns the viewport grid's size in cells.
+func (vp *Viewport) Size() gruid.Point {
+	return gruid.Point{X: vp.width, Y: vp.height}
+}
+
+// Rect returns the viewport's pixel region.
+func (vp *Viewport) Rect() image.Rectangle {
+	return vp.rect
+}
+
+// Flush draws the frame's cells into the viewport and presents the result.
+// Cells outside the viewport's grid are ignored. If the window content was
+// lost since the previous Flush, the whole viewport's grid is drawn again. As
+// with FlushErr, it returns a *FlushError if some cells could not be drawn.
+// It should be called from the main routine.
+func (vp *Viewport) Flush(frame gruid.Frame) error {
+	if !vp.dr.init {
+		return nil
+	}
+	vp.dr.beginDraw()
+	var ferr *FlushError
+	for _, fc := range frame.Cells {
+		p := fc.P
+		if p.X < 0 || p.X >= vp.width || p.Y < 0 || p.Y >= vp.height {
+			continue
+		}
+		vp.grid[p.X+vp.width*p.Y] = fc.Cell
+		if !vp.dirty {
+			ferr = addError(ferr, vp.draw(fc.Cell, p.X, p.Y))
+		}
+	}
+	if vp.dirty {
+		vp.dirty = false
+		for i, c := range vp.grid {
+			ferr = addError(ferr, vp.draw(c, i%vp.width, i/vp.width))
+		}
+	}
+	vp.dr.present()
+	if ferr != nil {
+		return ferr
+	}
+	return nil
+}
+
+// FlushGrid is like Flush, but draws the cells of the given grid that changed
+// since the last Flush. The grid's origin is drawn at the viewport's
+// top-left corner.
+func (vp *Viewport) FlushGrid(gd gruid.Grid) error {
+	frame := gruid.Frame{Width: vp.width, Height: vp.height, Time: time.Now()}
+	gd.Iter(func(p gruid.Point, c gruid.Cell) {
+		p = p.Sub(gd.Bounds().Min)
+		if p.X < 0 || p.X >= vp.width || p.Y < 0 || p.Y >= vp.height {
+			return
+		}
+		if vp.grid[p.X+vp.width*p.Y] != c {
+			frame.Cells = append(frame.Cells, gruid.FrameCell{Cell: c, P: p})
+		}
+	})
+	if len(frame.Cells) == 0 && !vp.dirty {
+		return nil
+	}
+	return vp.Flush(frame)
+}
+
+// Close removes the viewport from the driver and releases its textures. The
+// viewport's region is not cleared.
+func (vp *Viewport) Close() {
+	vp.ClearCache()
+	vps := vp.dr.viewports[:0]
+	for _, v := range vp.dr.viewports {
+		if v != vp {
+			vps = append(vps, v)
+		}
+	}
+	vp.dr.viewports = vps
+}
+
+// invalidateViewports records that the content of viewports was lost, so that
+// they are drawn again entirely on next Flush.
+func (dr *Driver) invalidateViewports() {
+	for _, vp := range dr.viewports {
+		vp.dirty = true
+	}
+}
+
+// ClearCache clears the viewport's tile textures cache.
+func (vp *Viewport) ClearCache() {
+	for c, tx := range vp.textures {
+		err := tx.Destroy()
+		if err != nil {
+			vp.dr.logf("viewport: texture destroy: %v", err)
+		}
+		delete(vp.textures, c)
+	}
+}
+
+func (vp *Viewport) draw(cell gruid.Cell, x, y int) error {
+	tx, ok := vp.textures[cell]
+	if !ok {
+		img, err := getTile(vp.tm, cell)
+		if err != nil || img == nil {
+			return &TileError{Cell: cell, Err: err}
+		}
+		tx, err = vp.dr.renderer.createTexture(img)
+		if err != nil {
+			return fmt.Errorf("viewport: texture: %v", err)
+		}
+		vp.textures[cell] = tx
+	}
+	rect := sdl.Rect{
+		X: int32(vp.rect.Min.X) + int32(x)*vp.tw,
+		Y: int32(vp.rect.Min.Y) + int32(y)*vp.th,
+		W: vp.tw,
+		H: vp.th,
+	}
+	err := vp.dr.renderer.copy(tx, nil, &rect)
+	if err != nil {
+		return fmt.Errorf("viewport: copy: %v", err)
+	}
+	return nil
+}
+
+// windowSize returns the size of the window in unscaled pixels, so that it
+// contains the main grid and all the viewports.
+func (dr *Driver) windowSize() (int32, int32) {
+	w, h := dr.gridSize()
+	for _, vp := range dr.viewports {
+		if int32(vp.rect.Max.X) > w {
+			w = int32(vp.rect.Max.X)
+		}
+		if int32(vp.rect.Max.Y) > h {
+			h = int32(vp.rect.Max.Y)
+		}
+	}
+	return w, h
+}
+
+// unscale converts window pixel coordinates into unscaled rendering
+// coordinates.
+func (dr *Driver) unscale(x, y int32) image.Point {
+	fx, fy := float32(x), float32(y)
+	if dr.scaleX > 0.1 && dr.scaleY > 0.1 {
+		fx /= dr.scaleX
+		fy /= dr.scaleY
+	}
+	fx, fy = dr.unzoom(fx, fy)
+	return image.Point{X: int(fx), Y: int(fy)}
+}
+
+// viewportAt returns the viewport containing the given window pixel
+// position, if any.
+func (dr *Driver) viewportAt(x, y int32) *Viewport {
+	p := dr.unscale(x, y)
+	for i := len(dr.viewports) - 1; i >= 0; i-- {
+		vp := dr.viewports[i]
+		if p.In(vp.rect) {
+			return vp
+		}
+	}
+	return nil
+}
+
+// cellAt returns the viewport cell for a window pixel position.
+func (vp *Viewport) cellAt(x, y int32) gruid.Point {
+	p := vp.dr.unscale(x, y).Sub(vp.rect.Min)
+	return gruid.Point{X: p.X / int(vp.tw), Y: p.Y / int(vp.th)}
+}
+
+// viewportMouseEvent translates a mouse event into a MsgViewportMouse, if it
+// concerns a viewport. It reports whether the event was handled.
+func (dr *Driver) viewportMouseEvent(event sdl.Event) (gruid.Msg, bool) {
+	if len(dr.viewports) == 0 {
+		return nil, false
+	}
+	msg := gruid.MsgMouse{Time: time.Now()}
+	var vp *Viewport
+	switch ev := event.(type) {
+	case *sdl.MouseButtonEvent:
+		if ev.Type == sdl.MOUSEBUTTONUP && dr.vpDrag != nil {
+			vp = dr.vpDrag
+			dr.vpDrag = nil
+			msg.Action = gruid.MouseRelease
+			msg.P = vp.cellAt(ev.X, ev.Y)
+			break
+		}
+		if dr.mousedrag != -1 {
+			return nil, false
+		}
+		vp = dr.viewportAt(ev.X, ev.Y)
+		if vp == nil {
+			return nil, false
+		}
+		if ev.Type != sdl.MOUSEBUTTONDOWN || dr.vpDrag != nil {
+			return nil, true
+		}
+		switch ev.Button {
+		case sdl.BUTTON_LEFT:
+			msg.Action = gruid.MouseMain
+		case sdl.BUTTON_MIDDLE:
+			msg.Action = gruid.MouseAuxiliary
+		case sdl.BUTTON_RIGHT:
+			msg.Action = gruid.MouseSecondary
+		default:
+			return nil, true
+		}
+		msg.P = vp.cellAt(ev.X, ev.Y)
+		dr.vpDrag = vp
+	case *sdl.MouseMotionEvent:
+		if dr.mousedrag != -1 {
+			return nil, false
+		}
+		vp = dr.vpDrag
+		if vp == nil {
+			vp = dr.viewportAt(ev.X, ev.Y)
+		}
+		if vp == nil {
+			return nil, false
+		}
+		msg.P = vp.cellAt(ev.X, ev.Y)
+		if msg.P == vp.mousepos {
+			return nil, true
+		}
+		vp.mousepos = msg.P
+		msg.Action = gruid.MouseMove
+	case *sdl.MouseWheelEvent:
+		vp = dr.viewportAt(int32(dr.mousePixel.X), int32(dr.mousePixel.Y))
+		if vp == nil {
+			return nil, false
+		}
+		switch {
+		case ev.Y > 0:
+			msg.Action = gruid.MouseWheelUp
+		case ev.Y < 0:
+			msg.Action = gruid.MouseWheelDown
+		default:
+			return nil, true
+		}
+		msg.P = vp.cellAt(int32(dr.mousePixel.X), int32(dr.mousePixel.Y))
+	default:
+		return nil, false
+	}
+	msg.Mod = dr.mouseMod()
+	return MsgViewportMouse{Viewport: vp, Mouse: msg}, true
+}
@@ -0,0 +1,177 @@
+package sdl
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// rendererFlags returns the sdl.RendererFlags used for creating the
+// renderer.
+func (dr *Driver) rendererFlags() uint32 {
+	var flags uint32
+	switch {
+	case dr.renderDriver != "":
+		// The named render driver determines acceleration.
+	case dr.accelerated:
+		flags = sdl.RENDERER_ACCELERATED
+	default:
+		flags = sdl.RENDERER_SOFTWARE
+	}
+	if dr.vsync {
+		flags |= sdl.RENDERER_PRESENTVSYNC
+	}
+	return flags
+}
+
+// SetVSync enables or disables synchronization of frame presentation with
+// the display refresh, avoiding tearing. As SDL2 does not provide a way to
+// change it for an existing renderer, the renderer is created again, and the
+// texture cache is rebuilt as tiles are drawn. If the driver is already
+// running, change will take effect with next Flush so that the function is
+// thread safe.
+func (dr *Driver) SetVSync(vsync bool) {
+	fn := func() {
+		if dr.vsync == vsync {
+			return
+		}
+		dr.vsync = vsync
+		if dr.init {
+			dr.recreateRenderer()
+		}
+	}
+	if dr.init {
+		dr.queueSet("SetVSync", fn)
+	} else {
+		fn()
+	}
+}
+
+// recreateRenderer replaces the renderer with a new one created with the
+// current flags, and requests a redraw. If the new renderer cannot be
+// created, the previous vsync setting is restored. If that fails too, the
+// renderer is replaced by a lostRenderer, so that subsequent flushes fail with
+// ErrRendererLost.
+func (dr *Driver) recreateRenderer() {
+	dr.ClearCache()
+	for _, vp := range dr.viewports {
+		vp.ClearCache()
+	}
+	dr.clearDragGhost()
+	dr.endTransition()
+	dr.destroyBackbuffer()
+	old := dr.renderer
+	// SDL2 does not allow more than one renderer per window.
+	err := old.Destroy()
+	if err != nil {
+		dr.logf("renderer destroy: %v", err)
+	}
+	r, err := dr.backend.createRenderer(dr.window, dr.renderDriver, dr.rendererFlags())
+	if err != nil {
+		dr.handleError(fmt.Errorf("vsync: renderer: %v", err))
+		dr.vsync = !dr.vsync
+		r, err = dr.backend.createRenderer(dr.window, dr.renderDriver, dr.rendererFlags())
+		if err != nil {
+			dr.handleError(fmt.Errorf("vsync: renderer: %v", err))
+			// The old renderer is destroyed: drawing is not possible
+			// anymore.
+			dr.renderer = lostRenderer{}
+			return
+		}
+	}
+	dr.renderer = r
+	if dr.compactTextures {
+		dr.setCompactTextures()
+	}
+	if dr.integerScale {
+		w, h := dr.windowSize()
+		dr.updateLogicalSize(w, h)
+	} else if dr.scaleX > 0.1 && dr.scaleY > 0.1 {
+		dr.setScale(dr.scaleX, dr.scaleY)
+	}
+	dr.invalidateViewports()
+	err = dr.clear()
+	if err != nil {
+		dr.logf("renderer clear: %v", err)
+	}
+	select {
+	case dr.reqredraw <- true:
+	default:
+	}
+}
+
+// ErrRendererLost is returned by FlushErr when the renderer could not be
+// created again after a change requiring it, like SetVSync, so that no
+// drawing is possible anymore.
+var ErrRendererLost = errors.New("renderer lost")
+
+// lostRenderer implements renderer for a driver whose renderer was destroyed
+// and could not be created again. All operations fail with ErrRendererLost.
+type lostRenderer struct{}
+
+func (lostRenderer) SetScale(scaleX, scaleY float32) error   { return ErrRendererLost }
+func (lostRenderer) SetDrawColor(r, g, b, a uint8) error     { return ErrRendererLost }
+func (lostRenderer) SetDrawBlendMode(bm sdl.BlendMode) error { return ErrRendererLost }
+func (lostRenderer) FillRect(rect *sdl.Rect) error           { return ErrRendererLost }
+func (lostRenderer) FillRects(rects []sdl.Rect) error        { return ErrRendererLost }
+func (lostRenderer) SetClipRect(rect *sdl.Rect) error        { return ErrRendererLost }
+func (lostRenderer) Clear() error                            { return ErrRendererLost }
+func (lostRenderer) Present()                                {}
+func (lostRenderer) Destroy() error                          { return nil }
+
+func (lostRenderer) GetInfo() (sdl.RendererInfo, error) {
+	return sdl.RendererInfo{}, ErrRendererLost
+}
+
+func (lostRenderer) createTexture(img image.Image) (texture, error) {
+	return nil, ErrRendererLost
+}
+
+func (lostRenderer) stageTexture(img image.Image) (staged, error) {
+	return nil, ErrRendererLost
+}
+
+func (lostRenderer) uploadTexture(st staged) (texture, error) {
+	st.free()
+	return nil, ErrRendererLost
+}
+
+func (lostRenderer) setTextureFormats(opaque, alpha uint32) {}
+
+func (lostRenderer) setLogicalSize(w, h int32, integer bool) error {
+	return ErrRendererLost
+}
+
+func (lostRenderer) createAtlas(w, h int32) (texture, error) {
+	return nil, ErrRendererLost
+}
+
+func (lostRenderer) createStreaming(w, h int32) (texture, error) {
+	return nil, ErrRendererLost
+}
+
+func (lostRenderer) updateTexture(tx texture, rect sdl.Rect, img *image.NRGBA) error {
+	return ErrRendererLost
+}
+
+func (lostRenderer) createTarget(w, h int32) (texture, error) {
+	return nil, ErrRendererLost
+}
+
+func (lostRenderer) setTarget(tx texture) error {
+	return ErrRendererLost
+}
+
+func (lostRenderer) copy(tx texture, src, dst *sdl.Rect) error {
+	return ErrRendererLost
+}
+
+func (lostRenderer) copyEx(tx texture, src, dst *sdl.Rect, angle float64, flip sdl.RendererFlip) error {
+	return ErrRendererLost
+}
+
+func (lostRenderer) readPixels() (*image.RGBA, error) {
+	return nil, ErrRendererLost
+}
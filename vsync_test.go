@@ -0,0 +1,116 @@
+package sdl
+
+import (
+	"errors"
+	"image"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// failingBackend is a headless backend whose renderer creation fails for
+// some flags.
+type failingBackend struct {
+	*headless
+	fail  func(flags uint32) bool
+	flags []uint32 // flags of created renderers
+}
+
+func (fb *failingBackend) createRenderer(win window, driver string, flags uint32) (renderer, error) {
+	if fb.fail(flags) {
+		return nil, errors.New("no renderer")
+	}
+	fb.flags = append(fb.flags, flags)
+	return fb.headless.createRenderer(win, driver, flags)
+}
+
+func TestRecreateRenderer(t *testing.T) {
+	tests := []struct {
+		name  string
+		fail  func(flags uint32) bool
+		vsync bool  // vsync after SetVSync(true)
+		errs  int   // number of reported errors
+		err   error // error returned by FlushErr
+	}{
+		{"ok", func(uint32) bool { return false }, true, 0, nil},
+		{"fallback", func(flags uint32) bool { return flags&sdl.RENDERER_PRESENTVSYNC != 0 }, false, 1, nil},
+		{"lost", func(uint32) bool { return true }, false, 2, ErrRendererLost},
+	}
+	for _, tt := range tests {
+		var errs []error
+		dr, hl := newTestDriver(t, Config{ErrorHandler: func(err error) { errs = append(errs, err) }})
+		fb := &failingBackend{headless: hl, fail: tt.fail}
+		dr.backend = fb
+		dr.SetScale(2, 2)
+		dr.Flush(testFrame(10, 5, "a"))
+		dr.SetVSync(true)
+		err := dr.FlushErr(testFrame(10, 5, "b"))
+		if err != tt.err {
+			t.Errorf("%s: FlushErr returned %v, want %v", tt.name, err, tt.err)
+		}
+		if len(errs) != tt.errs {
+			t.Errorf("%s: %d reported errors (%v), want %d", tt.name, len(errs), errs, tt.errs)
+		}
+		if dr.vsync != tt.vsync {
+			t.Errorf("%s: vsync %v, want %v", tt.name, dr.vsync, tt.vsync)
+		}
+		if tt.err != nil {
+			if _, ok := dr.renderer.(lostRenderer); !ok {
+				t.Errorf("%s: renderer %T, want lostRenderer", tt.name, dr.renderer)
+			}
+			continue
+		}
+		if len(fb.flags) != 1 || fb.flags[0]&sdl.RENDERER_PRESENTVSYNC != 0 != tt.vsync {
+			t.Errorf("%s: bad renderer flags %v", tt.name, fb.flags)
+		}
+		// The new renderer keeps the scale and draws the whole grid.
+		if r := dr.renderer.(*headlessRenderer); r.scaleX != 2 || r.scaleY != 2 {
+			t.Errorf("%s: renderer scale %vx%v, want 2x2", tt.name, r.scaleX, r.scaleY)
+		}
+		img := screenshot(t, dr)
+		if got, want := img.RGBAAt(1, 1), testColor('b', 0); got != want {
+			t.Errorf("%s: color %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestLostRenderer(t *testing.T) {
+	var r lostRenderer
+	rect := &sdl.Rect{W: 1, H: 1}
+	errs := []error{
+		r.SetScale(1, 1),
+		r.SetDrawColor(0, 0, 0, 0),
+		r.SetDrawBlendMode(sdl.BLENDMODE_NONE),
+		r.FillRect(rect),
+		r.FillRects([]sdl.Rect{*rect}),
+		r.SetClipRect(rect),
+		r.Clear(),
+		r.setLogicalSize(1, 1, true),
+		r.updateTexture(nil, *rect, image.NewNRGBA(image.Rect(0, 0, 1, 1))),
+		r.setTarget(nil),
+		r.copy(nil, nil, rect),
+		r.copyEx(nil, nil, rect, 0, sdl.FLIP_NONE),
+	}
+	_, err := r.GetInfo()
+	errs = append(errs, err)
+	_, err = r.createTexture(image.NewNRGBA(image.Rect(0, 0, 1, 1)))
+	errs = append(errs, err)
+	_, err = r.stageTexture(image.NewNRGBA(image.Rect(0, 0, 1, 1)))
+	errs = append(errs, err)
+	_, err = r.createAtlas(1, 1)
+	errs = append(errs, err)
+	_, err = r.createStreaming(1, 1)
+	errs = append(errs, err)
+	_, err = r.createTarget(1, 1)
+	errs = append(errs, err)
+	_, err = r.readPixels()
+	errs = append(errs, err)
+	for i, err := range errs {
+		if err != ErrRendererLost {
+			t.Errorf("operation %d returned %v, want ErrRendererLost", i, err)
+		}
+	}
+	if err := r.Destroy(); err != nil {
+		t.Errorf("Destroy returned %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package sdl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// watchInterval is the interval between two checks of watched files.
+const watchInterval = 500 * time.Millisecond
+
+// WatchTiles watches the given tileset or font files for modification, until
+// the context is cancelled. When some file changes, the load function is
+// called to build a new TileManager, which then replaces the current one as
+// with SetTileManager, clearing the cache and requesting a redraw. This
+// allows artists to iterate on tiles while the application runs.
+//
+// Files are checked periodically in a separate goroutine. Errors from the
+// load function are reported to the error handler (see Config.ErrorHandler)
+// from that goroutine, and the current TileManager is kept.
+func (dr *Driver) WatchTiles(ctx context.Context, load func() (TileManager, error), paths ...string) {
+	mtimes := make([]time.Time, len(paths))
+	for i, path := range paths {
+		fi, err := os.Stat(path)
+		if err == nil {
+			mtimes[i] = fi.ModTime()
+		}
+	}
+	go func() {
+		t := time.NewTicker(watchInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			changed := false
+			for i, path := range paths {
+				fi, err := os.Stat(path)
+				if err != nil {
+					// The file may be temporarily missing while
+					// being saved.
+					continue
+				}
+				if !fi.ModTime().Equal(mtimes[i]) {
+					mtimes[i] = fi.ModTime()
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+			tm, err := load()
+			if err != nil {
+				dr.handleError(fmt.Errorf("watch tiles: %v", err))
+				continue
+			}
+			dr.SetTileManager(tm)
+			// Request a first redraw so that the new TileManager
+			// is installed by next Flush even if the application
+			// is idle.
+			select {
+			case dr.reqredraw <- true:
+			default:
+			}
+		}
+	}()
+}
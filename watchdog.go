@@ -0,0 +1,44 @@
+package sdl
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// FlushHang describes a Flush that did not complete within the configured
+// deadline (see Config.FlushDeadline).
+type FlushHang struct {
+	Start    time.Time     // time when the Flush started
+	Deadline time.Duration // configured deadline
+	Cells    int           // number of cells in the flushed frame
+	Stack    []byte        // stack traces of all goroutines at detection time
+}
+
+// maxHangStack is the maximum size of goroutine stack traces reported in a
+// FlushHang.
+const maxHangStack = 1 << 20
+
+// watchFlush starts a watchdog for a Flush started at the given time, and
+// returns a function to be called when the Flush completes. If the deadline
+// expires first, the hang handler (see Config.OnFlushHang) is called from the
+// watchdog's goroutine, as the main routine is then blocked.
+func (dr *Driver) watchFlush(cells int, start time.Time) func() {
+	var hung int32
+	t := time.AfterFunc(dr.flushDeadline, func() {
+		atomic.StoreInt32(&hung, 1)
+		buf := make([]byte, maxHangStack)
+		buf = buf[:runtime.Stack(buf, true)]
+		hang := FlushHang{Start: start, Deadline: dr.flushDeadline, Cells: cells, Stack: buf}
+		if dr.onFlushHang != nil {
+			dr.onFlushHang(hang)
+			return
+		}
+		dr.logf("flush watchdog: flush of %d cells not completed after %v\n%s", cells, hang.Deadline, hang.Stack)
+	})
+	return func() {
+		if !t.Stop() && atomic.LoadInt32(&hung) == 1 {
+			dr.logf("flush watchdog: flush completed after %v", time.Since(start))
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package sdl
+
+import "github.com/anaseto/gruid"
+
+// wideRanges contains ranges of East Asian wide and fullwidth runes, as well
+// as wide emoji, sorted in increasing order.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x231A, 0x231B},   // watch, hourglass
+	{0x2329, 0x232A},   // angle brackets
+	{0x23E9, 0x23EC},   // media control symbols
+	{0x23F0, 0x23F0},   // alarm clock
+	{0x23F3, 0x23F3},   // hourglass with flowing sand
+	{0x25FD, 0x25FE},   // medium small squares
+	{0x2614, 0x2615},   // umbrella, hot beverage
+	{0x2648, 0x2653},   // zodiac symbols
+	{0x26A1, 0x26A1},   // high voltage
+	{0x26AA, 0x26AB},   // medium circles
+	{0x26BD, 0x26BE},   // soccer ball, baseball
+	{0x26C4, 0x26C5},   // snowman, sun behind cloud
+	{0x26D4, 0x26D4},   // no entry
+	{0x26EA, 0x26EA},   // church
+	{0x26F2, 0x26F5},   // fountain, flag, sailboat
+	{0x26FA, 0x26FA},   // tent
+	{0x26FD, 0x26FD},   // fuel pump
+	{0x2705, 0x2705},   // check mark
+	{0x270A, 0x270B},   // raised fists
+	{0x2728, 0x2728},   // sparkles
+	{0x274C, 0x274C},   // cross mark
+	{0x2753, 0x2755},   // question and exclamation marks
+	{0x2757, 0x2757},   // heavy exclamation mark
+	{0x2795, 0x2797},   // heavy plus, minus, division
+	{0x27B0, 0x27B0},   // curly loop
+	{0x27BF, 0x27BF},   // double curly loop
+	{0x2B1B, 0x2B1C},   // large squares
+	{0x2B50, 0x2B50},   // star
+	{0x2B55, 0x2B55},   // heavy large circle
+	{0x2E80, 0x303E},   // CJK radicals, symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK unified ideographs extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo extended A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small form variants
+	{0xFF00, 0xFF60},   // fullwidth forms
+	{0xFFE0, 0xFFE6},   // fullwidth signs
+	{0x16FE0, 0x18AFF}, // Tangut, Khitan
+	{0x1B000, 0x1B2FF}, // Kana supplement and extensions, Nushu
+	{0x1F004, 0x1F004}, // mahjong tile red dragon
+	{0x1F0CF, 0x1F0CF}, // playing card black joker
+	{0x1F18E, 0x1F18E}, // negative squared AB
+	{0x1F191, 0x1F19A}, // squared words
+	{0x1F200, 0x1F2FF}, // enclosed ideographic supplement
+	{0x1F300, 0x1F64F}, // pictographs, emoticons
+	{0x1F680, 0x1F6FF}, // transport and map symbols
+	{0x1F7E0, 0x1F7EB}, // large colored circles and squares
+	{0x1F90C, 0x1F9FF}, // supplemental symbols and pictographs
+	{0x1FA70, 0x1FAFF}, // symbols and pictographs extended A
+	{0x20000, 0x3FFFD}, // CJK unified ideographs extensions
+}
+
+// isWide reports whether a rune is usually displayed over two cells, such as
+// CJK ideographs and fullwidth forms.
+func isWide(r rune) bool {
+	if r < wideRanges[0][0] {
+		return false
+	}
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		m := (lo + hi) / 2
+		switch {
+		case r < wideRanges[m][0]:
+			hi = m
+		case r > wideRanges[m][1]:
+			lo = m + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// wideAt reports whether the cell at index i of the grid is a wide rune
+// spanning over the next cell. A wide rune covered by a previous one is
+// not drawn, so that in a sequence of wide runes, only every other one
+// spans over the next cell.
+func (dr *Driver) wideAt(i int) bool {
+	w := int(dr.width)
+	if !dr.wideRunes || i%w == w-1 || !isWide(dr.grid[i].Rune) {
+		return false
+	}
+	n := 0 // number of wide runes before i in the sequence
+	for j := i - 1; j >= 0 && j%w < w-1 && isWide(dr.grid[j].Rune); j-- {
+		n++
+	}
+	return n%2 == 0
+}
+
+// covered reports whether the cell at index i of the grid is covered by a
+// wide rune in the previous cell.
+func (dr *Driver) covered(i int) bool {
+	return i%int(dr.width) > 0 && dr.wideAt(i-1)
+}
+
+// drawFrameWide is like drawFrame, but handles double-width runes: when a
+// cell changes, neighbouring cells affected by a wide rune are redrawn too.
+func (dr *Driver) drawFrameWide(frame gruid.Frame) *FlushError {
+	w, h := int(dr.width), int(dr.height)
+	if len(dr.dirty) != w*h {
+		dr.dirty = make([]bool, w*h)
+	}
+	overlays := dr.hasOverlays()
+	full := overlays || dr.overlaid || dr.fullRedraw || len(dr.oversized) > 0
+	dr.fullRedraw = false
+	for _, fc := range frame.Cells {
+		if fc.P.X < 0 || fc.P.X >= w || fc.P.Y < 0 || fc.P.Y >= h {
+			continue
+		}
+		i := fc.P.X + w*fc.P.Y
+		wide := isWide(dr.grid[i].Rune)
+		dr.grid[i] = fc.Cell
+		dr.dirty[i] = true
+		// Which of the following cells are covered may change up to
+		// the end of the sequence of wide runes.
+		for j := i + 1; j%w > 0 && (wide || isWide(dr.grid[j-1].Rune)); j++ {
+			dr.dirty[j] = true
+			wide = false
+		}
+	}
+	oversized := len(dr.oversized)
+	ferr := dr.drawWideCells(full)
+	if len(dr.oversized) > oversized {
+		// Newly found oversized tiles might have been overlapped by
+		// neighbors drawn afterwards.
+		ferr = mergeErrors(ferr, dr.drawWideCells(true))
+	}
+	dr.drawPadding()
+	if overlays {
+		dr.drawOverlays()
+	}
+	dr.overlaid = overlays
+	return ferr
+}
+
+// drawWideCells draws the dirty cells of the grid, or all of them if full is
+// true, taking wide runes into account.
+func (dr *Driver) drawWideCells(full bool) *FlushError {
+	w := int(dr.width)
+	var ferr *FlushError
+	for i, c := range dr.grid {
+		if !full && !dr.dirty[i] {
+			continue
+		}
+		dr.dirty[i] = false
+		if dr.covered(i) {
+			if !full {
+				// Redraw the wide rune in the previous cell.
+				ferr = addError(ferr, dr.draw(dr.grid[i-1], (i-1)%w, i/w))
+			}
+			continue
+		}
+		ferr = addError(ferr, dr.draw(c, i%w, i/w))
+	}
+	return ferr
+}
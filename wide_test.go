@@ -0,0 +1,97 @@
+package sdl
+
+import (
+	"testing"
+)
+
+func TestIsWide(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want bool
+	}{
+		{'a', false},
+		{'é', false},
+		{'─', false},
+		{'中', true},
+		{'한', true},
+		{'あ', true},
+		{'Ａ', true},
+		{'😀', true},
+		{0x20000, true},
+	}
+	for _, tt := range tests {
+		if got := isWide(tt.r); got != tt.want {
+			t.Errorf("isWide(%q) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestWideAt(t *testing.T) {
+	tests := []struct {
+		row  string
+		wide string // x for each cell spanning over the next one
+	}{
+		{"a中b", " x "},
+		{"中中中a", "x x "},
+		{"a中中中", " x  "}, // no next cell for the last one
+		{"ab中", "  x"},
+		{"abc中", "    "},
+	}
+	for _, tt := range tests {
+		dr, _ := newTestDriver(t, Config{WideRunes: true, Width: 4, Height: 2})
+		dr.Flush(testFrame(4, 2, tt.row, tt.row))
+		for y := 0; y < 2; y++ {
+			for x, c := range tt.wide {
+				if got := dr.wideAt(x + 4*y); got != (c == 'x') {
+					t.Errorf("%q: wideAt(%d, %d) = %v", tt.row, x, y, got)
+				}
+			}
+		}
+	}
+}
+
+func TestWideRunes(t *testing.T) {
+	// Each frame changes the first row, and rendering is compared with a
+	// full redraw.
+	tests := [][]string{
+		{"a中bcd", "aebcd"},
+		{"a中bcd", "ab中cd", "abc中d", "abcd中"},
+		{"中中中a", "b中中a", "bb中a", "中中中中"},
+		{"中bcd", "中中cd", "中中中d", "中中中中"},
+		{"abcd中", "abcde"},
+	}
+	for _, rows := range tests {
+		dr, _ := newTestDriver(t, Config{WideRunes: true})
+		ref, _ := newTestDriver(t, Config{WideRunes: true})
+		for i, row := range rows {
+			frame := testFrame(10, 5, row)
+			dr.Flush(frame)
+			ref.fullRedraw = true
+			ref.Flush(frame)
+			if p, ok := sameImages(screenshot(t, dr), screenshot(t, ref)); !ok {
+				t.Errorf("%q: frame %d: rendering differs at %v", rows, i, p)
+				break
+			}
+		}
+		// Wide runes are drawn over two cells.
+		img := screenshot(t, dr)
+		last := []rune(rows[len(rows)-1])
+		for x, r := range last {
+			if !isWide(r) || dr.covered(x) {
+				continue
+			}
+			got := img.RGBAAt((x+1)*testTileSize+testTileSize/2, testTileSize/2)
+			if want := testColor(r, 1); got != want {
+				t.Errorf("%q: next cell of %c at %d has color %v, want %v", rows, r, x, got, want)
+			}
+		}
+	}
+}
+
+func TestWideRunesDisabled(t *testing.T) {
+	dr, _ := newTestDriver(t, Config{})
+	dr.Flush(testFrame(10, 5, "中b"))
+	if dr.wideAt(0) || dr.covered(1) {
+		t.Error("wide rune handling without Config.WideRunes")
+	}
+}
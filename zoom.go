@@ -0,0 +1,129 @@
+package sdl
+
+import (
+	"github.com/anaseto/gruid"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+const (
+	maxZoom  = 8    // maximum zoom factor
+	zoomStep = 1.25 // zoom factor change for each Ctrl+wheel step
+)
+
+// zoom describes the visible part of the rendered content while zoomed.
+type zoom struct {
+	factor float32 // zoom factor, or zero if not zoomed
+	ox, oy float32 // top-left visible position, in rendering coordinates
+}
+
+// Zoom magnifies the rendered content by the given factor, keeping the
+// given cell at the same place in the window, as when zooming around the cell
+// under the mouse. A factor of 1 or less restores normal rendering. Mouse
+// coordinates are mapped accordingly. Zooming draws into an offscreen
+// backbuffer, as with Config.Backbuffer. If the driver is already running,
+// change will take effect with next Flush so that the function is thread
+// safe.
+func (dr *Driver) Zoom(factor float32, center gruid.Point) {
+	fn := func() {
+		dr.zoomAt(factor, center)
+	}
+	if dr.init {
+		dr.queue(fn)
+	} else {
+		fn()
+	}
+}
+
+// zoomed reports whether the rendered content is currently zoomed.
+func (dr *Driver) zoomed() bool {
+	return dr.zoom.factor > 1
+}
+
+// zoomFactor returns the current zoom factor.
+func (dr *Driver) zoomFactor() float32 {
+	if dr.zoom.factor > 1 {
+		return dr.zoom.factor
+	}
+	return 1
+}
+
+// zoomAt changes the zoom factor around the given cell.
+func (dr *Driver) zoomAt(factor float32, center gruid.Point) {
+	if factor > maxZoom {
+		factor = maxZoom
+	}
+	if factor <= 1 {
+		if dr.zoomed() {
+			dr.zoom = zoom{}
+			dr.fullRedraw = true
+			dr.needRefresh = true
+		}
+		return
+	}
+	old := dr.zoomFactor()
+	cx := (float32(center.X) + 0.5) * float32(dr.tw)
+	cy := (float32(center.Y) + 0.5) * float32(dr.th)
+	// window position of the center, for the previous zoom
+	wx := (cx - dr.zoom.ox) * old
+	wy := (cy - dr.zoom.oy) * old
+	w, h := dr.windowSize()
+	dr.zoom = zoom{
+		factor: factor,
+		ox:     clampZoom(cx-wx/factor, float32(w)*(1-1/factor)),
+		oy:     clampZoom(cy-wy/factor, float32(h)*(1-1/factor)),
+	}
+	// The backbuffer might not be up to date.
+	dr.fullRedraw = true
+	dr.needRefresh = true
+}
+
+func clampZoom(o, max float32) float32 {
+	if o > max {
+		o = max
+	}
+	if o < 0 {
+		o = 0
+	}
+	return o
+}
+
+// zoomRect returns the backbuffer part shown in the window.
+func (dr *Driver) zoomRect() *sdl.Rect {
+	if !dr.zoomed() {
+		return nil
+	}
+	f := dr.zoom.factor
+	return &sdl.Rect{
+		X: int32(dr.zoom.ox),
+		Y: int32(dr.zoom.oy),
+		W: int32(float32(dr.bbSize.X) / f),
+		H: int32(float32(dr.bbSize.Y) / f),
+	}
+}
+
+// unzoom converts unscaled window coordinates into rendering coordinates.
+func (dr *Driver) unzoom(x, y float32) (float32, float32) {
+	if !dr.zoomed() {
+		return x, y
+	}
+	return dr.zoom.ox + x/dr.zoom.factor, dr.zoom.oy + y/dr.zoom.factor
+}
+
+// wheelZoom handles Ctrl+wheel zooming, if enabled. It reports whether the
+// event was handled.
+func (dr *Driver) wheelZoom(ev *sdl.MouseWheelEvent) bool {
+	if !dr.zoomWheel || ev.Y == 0 || dr.mouseMod()&gruid.ModCtrl == 0 {
+		return false
+	}
+	factor := dr.zoomFactor()
+	if ev.Y > 0 {
+		factor *= zoomStep
+	} else {
+		factor /= zoomStep
+	}
+	if factor < 1.01 {
+		factor = 1
+	}
+	dr.zoomAt(factor, dr.mousepos)
+	return true
+}